@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// dotenvKeyRegexp matches the variable names that godotenv can parse.
+var dotenvKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// dotenvEscaper escapes a value for use in double quotes, so that newlines
+// and variable references are read back verbatim.
+var dotenvEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`", "\n", `\n`, "\r", `\r`)
+
+// encodeDotenv renders variables as a .env file, one KEY="VALUE" line per
+// variable sorted by name. godotenv.Marshal is not used since it writes values
+// that look like integers as numbers, turning e.g. "007" into 7.
+func encodeDotenv(values map[string]string) (string, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		line, err := encodeDotenvLine(key, values[key])
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String(), nil
+}
+
+// encodeDotenvLine renders a single variable, double-quoted or, if godotenv
+// can't read that back, single-quoted or unquoted.
+func encodeDotenvLine(key string, value string) (string, error) {
+	if !dotenvKeyRegexp.MatchString(key) {
+		return "", fmt.Errorf("invalid variable name %q: names must start with a letter or underscore and contain only letters, digits, underscores and dots", key)
+	}
+
+	// godotenv can't read back quoted values that end in a backslash or a
+	// quote, so make sure that the value survives a round trip
+	candidates := []string{
+		key + `="` + dotenvEscaper.Replace(value) + `"`,
+		key + `='` + value + `'`,
+		key + `=` + value,
+	}
+	for _, line := range candidates {
+		decoded, err := godotenv.Unmarshal(line)
+		if err == nil && len(decoded) == 1 && decoded[key] == value {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("the value of %s can't be represented in a .env file", key)
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/joho/godotenv"
+)
+
+var _ function.Function = &DotenvDecodeFunction{}
+
+func NewDotenvDecodeFunction() function.Function {
+	return &DotenvDecodeFunction{}
+}
+
+type DotenvDecodeFunction struct{}
+
+func (f *DotenvDecodeFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "dotenv_decode"
+}
+
+func (f *DotenvDecodeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parse a .env file",
+		Description: "Parses the content of a .env file into a map of variable names to values. Supports comments, export prefixes, single- and double-quoted values, and references to variables defined earlier in the file.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "content", Description: "Content of the .env file"},
+		},
+		Return: function.MapReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *DotenvDecodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &content))
+	if resp.Error != nil {
+		return
+	}
+
+	values, err := godotenv.Unmarshal(content)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid .env content: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, values))
+}
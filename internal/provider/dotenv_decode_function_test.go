@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccDotenvDecodeFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "values" {
+  value = provider::tf::dotenv_decode(<<-EOT
+    # Database
+    export DB_HOST=localhost
+    DB_PORT=5432
+    DB_URL="postgres://$${DB_HOST}:$${DB_PORT}/app"
+    GREETING='Hello, "World"'
+    EOT
+  )
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("values", knownvalue.MapExact(map[string]knownvalue.Check{
+						"DB_HOST":  knownvalue.StringExact("localhost"),
+						"DB_PORT":  knownvalue.StringExact("5432"),
+						"DB_URL":   knownvalue.StringExact("postgres://localhost:5432/app"),
+						"GREETING": knownvalue.StringExact(`Hello, "World"`),
+					})),
+				},
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::tf::dotenv_decode("DB_HOST=\"localhost")
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid value for "content" parameter`),
+			},
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &DotenvEncodeFunction{}
+
+func NewDotenvEncodeFunction() function.Function {
+	return &DotenvEncodeFunction{}
+}
+
+type DotenvEncodeFunction struct{}
+
+func (f *DotenvEncodeFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "dotenv_encode"
+}
+
+func (f *DotenvEncodeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Render a .env file",
+		Description: "Renders a map of variable names to values as the content of a .env file, with one KEY=\"VALUE\" line per variable sorted by name. Quotes, backslashes, dollar signs and newlines in values are escaped, so that dotenv_decode returns the original values.",
+		Parameters: []function.Parameter{
+			function.MapParameter{Name: "values", ElementType: types.StringType, Description: "Variable names and values"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *DotenvEncodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var values map[string]string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &values))
+	if resp.Error != nil {
+		return
+	}
+
+	content, err := encodeDotenv(values)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, content))
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccDotenvEncodeFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  values = {
+    DB_PORT  = "5432"
+    DB_URL   = "postgres://$${USER}@localhost/app"
+    MOTD     = "Line 1\nLine 2"
+  }
+}
+
+output "content" {
+  value = provider::tf::dotenv_encode(local.values)
+}
+
+# Decoding returns the original values
+output "round_trip" {
+  value = provider::tf::dotenv_decode(provider::tf::dotenv_encode(local.values)) == tomap(local.values)
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("content", knownvalue.StringExact("DB_PORT=\"5432\"\nDB_URL=\"postgres://\\${USER}@localhost/app\"\nMOTD=\"Line 1\\nLine 2\"\n")),
+					statecheck.ExpectKnownOutputValue("round_trip", knownvalue.Bool(true)),
+				},
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::tf::dotenv_encode({ "DB-HOST" = "localhost" })
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid value for "values" parameter`),
+			},
+		},
+	})
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/joho/godotenv"
+)
+
+type DotenvFileDataSourceModel struct {
+	Path   types.String `tfsdk:"path"`
+	Values types.Map    `tfsdk:"values"`
+	Id     types.String `tfsdk:"id"`
+}
+
+var DotenvFileDataSourceSchema = schema.Schema{
+	Description: "Read .env files",
+	Attributes: map[string]schema.Attribute{
+		"path":   schema.StringAttribute{Required: true, Description: "Path to the .env file"},
+		"values": schema.MapAttribute{ElementType: types.StringType, Computed: true, Sensitive: true, Description: "Variables defined in the file, parsed the same way as by dotenv_decode. Sensitive, since .env files commonly hold secrets."},
+		"id":     schema.StringAttribute{Computed: true, Description: "Absolute path of the file"},
+	},
+}
+
+var _ datasource.DataSource = &DotenvFileDataSource{}
+
+func NewDotenvFileDataSource() datasource.DataSource {
+	return &DotenvFileDataSource{}
+}
+
+type DotenvFileDataSource struct {
+	config *localProviderConfig
+}
+
+func (d *DotenvFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_dotenv_file"
+}
+
+func (d *DotenvFileDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = DotenvFileDataSourceSchema
+}
+
+func (d *DotenvFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*localProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *localProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+	d.config = config
+}
+
+func (d *DotenvFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DotenvFileDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := d.config.checkPath(data.Path.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+		return
+	}
+
+	id, err := fileID(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+		return
+	}
+	data.Id = types.StringValue(id)
+
+	content, err := os.ReadFile(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", err.Error())
+		return
+	}
+	values, err := godotenv.UnmarshalBytes(content)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid .env file", fmt.Sprintf("Failed to parse %s: %v", data.Path.ValueString(), err))
+		return
+	}
+
+	valuesMap, diags := types.MapValueFrom(ctx, types.StringType, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Values = valuesMap
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
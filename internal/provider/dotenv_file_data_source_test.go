@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccDotenvFileDataSource(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(path, []byte("# App settings\nAPP_ENV=production\nAPP_SECRET=\"s3cr3t\\nvalue\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(tempDir, "invalid.env")
+	if err := os.WriteFile(invalid, []byte("APP_ENV=\"production\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "tf_dotenv_file" "test" {
+  path = "%s"
+}
+`, invalid),
+				ExpectError: regexp.MustCompile(`Invalid .env file`),
+			},
+			{
+				Config: fmt.Sprintf(`
+data "tf_dotenv_file" "test" {
+  path = "%s"
+}
+`, path),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tf_dotenv_file.test", "id", path),
+					resource.TestCheckResourceAttr("data.tf_dotenv_file.test", "values.%", "2"),
+					resource.TestCheckResourceAttr("data.tf_dotenv_file.test", "values.APP_ENV", "production"),
+					resource.TestCheckResourceAttr("data.tf_dotenv_file.test", "values.APP_SECRET", "s3cr3t\nvalue"),
+				),
+			},
+		},
+	})
+}
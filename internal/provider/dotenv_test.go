@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joho/godotenv"
+)
+
+func TestEncodeDotenv(t *testing.T) {
+	values := map[string]string{
+		"PORT":      "8080",
+		"ZIP_CODE":  "007",
+		"EMPTY":     "",
+		"GREETING":  `Say "hello"`,
+		"MULTILINE": "first\nsecond",
+		"LITERAL":   "$HOME and ${PATH}",
+		"WINDOWS":   `C:\Program Files\`,
+		"QUOTED":    `"quoted"`,
+		"app.name":  "demo",
+	}
+
+	content, err := encodeDotenv(values)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Values are double-quoted and escaped where godotenv can read them back
+	for _, line := range []string{`EMPTY=""`, `MULTILINE="first\nsecond"`, `LITERAL="\$HOME and \${PATH}"`, `ZIP_CODE="007"`, `GREETING='Say "hello"'`} {
+		if !strings.Contains(content, line+"\n") {
+			t.Errorf("expected line %s in content:\n%s", line, content)
+		}
+	}
+
+	decoded, err := godotenv.Unmarshal(content)
+	if err != nil {
+		t.Fatalf("failed to decode:\n%s\n%v", content, err)
+	}
+	for key, value := range values {
+		if decoded[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, decoded[key])
+		}
+	}
+	if len(decoded) != len(values) {
+		t.Errorf("expected %d values, got %d", len(values), len(decoded))
+	}
+
+	if _, err := encodeDotenv(map[string]string{"MY-KEY": "value"}); err == nil || !strings.Contains(err.Error(), `invalid variable name "MY-KEY"`) {
+		t.Errorf("expected invalid variable name error, got %v", err)
+	}
+	if content, err := encodeDotenv(nil); err != nil || content != "" {
+		t.Errorf("expected empty content, got %q (%v)", content, err)
+	}
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// execPolicy restricts the commands that tf_local_exec may run. The zero
+// value allows every command.
+type execPolicy struct {
+	Disabled           bool
+	AllowedExecutables []allowedExecutable
+	DeniedCommands     []deniedCommand
+}
+
+// allowedExecutable is an entry of allowed_executables, with the path it
+// resolves to. Path is empty if the executable could not be found.
+type allowedExecutable struct {
+	Name string
+	Path string
+}
+
+// deniedCommand is an entry of denied_commands.
+type deniedCommand struct {
+	Pattern string
+	regexp  *regexp.Regexp
+}
+
+// execPolicyError describes why a command is not allowed by the policy.
+type execPolicyError struct {
+	Command string
+	Reason  string
+}
+
+func (e *execPolicyError) Error() string {
+	return fmt.Sprintf("command %q is not allowed: %s", e.Command, e.Reason)
+}
+
+// execPolicy returns the policy described by the exec_policy block. A nil
+// block allows every command.
+func (m *LocalExecPolicyModel) execPolicy(ctx context.Context) (execPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var policy execPolicy
+	if m == nil {
+		return policy, diags
+	}
+	policyPath := path.Root("exec_policy")
+
+	policy.Disabled = m.DisableExec.ValueBool()
+
+	allowed, d := listValueToStrings(ctx, m.AllowedExecutables)
+	diags.Append(d...)
+	for _, name := range allowed {
+		// Executables that can't be found are kept, so that they are listed in errors
+		resolved, _ := resolveExecutable(name, "")
+		policy.AllowedExecutables = append(policy.AllowedExecutables, allowedExecutable{Name: name, Path: resolved})
+	}
+
+	denied, d := listValueToStrings(ctx, m.DeniedCommands)
+	diags.Append(d...)
+	for _, pattern := range denied {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			diags.AddAttributeError(policyPath.AtName("denied_commands"), "Invalid exec policy", fmt.Sprintf("invalid regular expression %q: %v", pattern, err))
+			continue
+		}
+		policy.DeniedCommands = append(policy.DeniedCommands, deniedCommand{Pattern: pattern, regexp: re})
+	}
+
+	return policy, diags
+}
+
+// check returns an *execPolicyError if the policy does not allow command.
+func (p execPolicy) check(command localCommand) error {
+	if p.Disabled {
+		return &execPolicyError{Command: command.String(), Reason: "command execution is disabled by exec_policy.disable_exec"}
+	}
+
+	// The command is matched on its own, so that anchored patterns work, and
+	// as the full command line, so that interpreter arguments can't bypass them
+	commandLines := []string{command.String(), strings.Join(command.args(), " ")}
+	for _, denied := range p.DeniedCommands {
+		for _, line := range commandLines {
+			if denied.regexp.MatchString(line) {
+				return &execPolicyError{Command: line, Reason: fmt.Sprintf("matches exec_policy.denied_commands rule %q", denied.Pattern)}
+			}
+		}
+	}
+
+	if len(p.AllowedExecutables) == 0 {
+		return nil
+	}
+	program := command.args()[0]
+	resolved, err := resolveExecutable(program, command.WorkingDir)
+	if err == nil {
+		for _, allowed := range p.AllowedExecutables {
+			if allowed.Path == resolved {
+				return nil
+			}
+		}
+	}
+	names := make([]string, len(p.AllowedExecutables))
+	for i, allowed := range p.AllowedExecutables {
+		names[i] = fmt.Sprintf("%q", allowed.Name)
+	}
+	reason := fmt.Sprintf("executable %s", program)
+	switch {
+	case err != nil:
+		reason += fmt.Sprintf(" could not be resolved (%v), and", err)
+	case resolved != program:
+		reason += fmt.Sprintf(" resolves to %s, which", resolved)
+	}
+	reason += fmt.Sprintf(" is not matched by any exec_policy.allowed_executables rule (%s)", strings.Join(names, ", "))
+	return &execPolicyError{Command: command.String(), Reason: reason}
+}
+
+// resolveExecutable returns the absolute path of an executable with symlinks
+// resolved, the way it is found when the command runs: names are looked up in
+// PATH, and relative paths are resolved against the working directory.
+func resolveExecutable(name string, workingDir string) (string, error) {
+	if !strings.ContainsRune(name, filepath.Separator) {
+		found, err := exec.LookPath(name)
+		if err != nil {
+			return "", err
+		}
+		name = found
+	} else if !filepath.IsAbs(name) && workingDir != "" {
+		name = filepath.Join(workingDir, name)
+	}
+	absolute, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(absolute)
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestExecPolicyCheck(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	tempDir := t.TempDir()
+	link := filepath.Join(tempDir, "shell")
+	if err := os.Symlink(sh, link); err != nil {
+		t.Fatal(err)
+	}
+
+	model := &LocalExecPolicyModel{
+		AllowedExecutables: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("sh"), types.StringValue("no-such-executable")}),
+		DeniedCommands:     types.ListValueMust(types.StringType, []attr.Value{types.StringValue(`\brm\s+-rf\b`)}),
+		DisableExec:        types.BoolNull(),
+	}
+	policy, diags := model.execPolicy(context.Background())
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	tests := []struct {
+		name    string
+		command localCommand
+		error   string
+	}{
+		{name: "default interpreter", command: localCommand{Command: "echo hello", Interpreter: defaultInterpreter}},
+		{name: "interpreter path", command: localCommand{Command: "echo hello", Interpreter: []string{sh, "-c"}}},
+		// Symlinks are resolved, so a link to an allowed executable is allowed
+		{name: "symlink", command: localCommand{Argv: []string{"./shell", "-c", "true"}, WorkingDir: tempDir}},
+		{name: "not allowed", command: localCommand{Argv: []string{"env"}}, error: "is not matched by any exec_policy.allowed_executables rule"},
+		{name: "not found", command: localCommand{Argv: []string{"no-such-executable"}}, error: "could not be resolved"},
+		{name: "denied", command: localCommand{Command: "rm -rf /", Interpreter: defaultInterpreter}, error: `matches exec_policy.denied_commands rule "\\brm\\s+-rf\\b"`},
+		// Interpreter arguments are part of the command line that is matched
+		{name: "denied in interpreter", command: localCommand{Command: "echo hi", Interpreter: []string{"sh", "-c", "rm -rf /tmp/x", "--"}}, error: `"sh -c rm -rf /tmp/x -- echo hi" is not allowed: matches exec_policy.denied_commands rule`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.check(tt.command)
+			if tt.error == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+
+	disabled := execPolicy{Disabled: true}
+	if err := disabled.check(localCommand{Command: "true"}); err == nil || !strings.Contains(err.Error(), "disable_exec") {
+		t.Errorf("expected disable_exec error, got %v", err)
+	}
+
+	// A nil block allows everything
+	var none *LocalExecPolicyModel
+	policy, _ = none.execPolicy(context.Background())
+	if err := policy.check(localCommand{Argv: []string{"no-such-executable"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExecPolicyInvalidPattern(t *testing.T) {
+	model := &LocalExecPolicyModel{
+		AllowedExecutables: types.ListNull(types.StringType),
+		DeniedCommands:     types.ListValueMust(types.StringType, []attr.Value{types.StringValue("(")}),
+	}
+	if _, diags := model.execPolicy(context.Background()); !diags.HasError() {
+		t.Errorf("expected error for invalid regular expression")
+	}
+}
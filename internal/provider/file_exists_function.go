@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &FileExistsFunction{}
+
+func NewFileExistsFunction() function.Function {
+	return &FileExistsFunction{}
+}
+
+type FileExistsFunction struct{}
+
+func (f *FileExistsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "file_exists"
+}
+
+func (f *FileExistsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Whether a file exists",
+		Description: "Returns whether a file or directory exists at the given path, following symlinks. Relative paths are resolved against TF_LOCAL_BASE_DIR, or the directory Terraform is run from.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "path", Description: "Path to the file"},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *FileExistsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var path string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &path))
+	if resp.Error != nil {
+		return
+	}
+
+	resolved, err := functionPath(path)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	_, err = os.Stat(resolved)
+	if err != nil && !os.IsNotExist(err) {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Failed to access file: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, err == nil))
+}
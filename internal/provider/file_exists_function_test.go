@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccFileExistsFunction(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("Hello, World!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(baseDirEnvVar, tempDir)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "file" {
+  value = provider::tf::file_exists("test.txt")
+}
+
+output "directory" {
+  value = provider::tf::file_exists(".")
+}
+
+output "missing" {
+  value = provider::tf::file_exists("missing.txt")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("file", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("directory", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("missing", knownvalue.Bool(false)),
+				},
+			},
+		},
+	})
+}
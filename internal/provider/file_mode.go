@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Modes that symbolic notation is applied to, which are the modes chmod starts
+// from for a new file or directory when no umask applies.
+const (
+	defaultFileMode      fs.FileMode = 0666
+	defaultDirectoryMode fs.FileMode = 0777
+)
+
+// parseFileMode parses a file mode given either in octal notation, e.g. "0644",
+// or in symbolic notation, e.g. "u=rw,go=r". Symbolic modes are applied to the
+// base mode, so that relative clauses such as "go-w" have something to modify:
+// with defaultFileMode, "go-w", "u=rw,go=r" and "a=r,u+w" all result in 0644.
+func parseFileMode(mode string, base fs.FileMode) (fs.FileMode, error) {
+	if mode == "" {
+		return 0, fmt.Errorf("file mode must not be empty")
+	}
+	if mode[0] >= '0' && mode[0] <= '9' {
+		return parseOctalFileMode(mode)
+	}
+	return parseSymbolicFileMode(mode, base)
+}
+
+// formatFileMode formats a file mode in four-digit octal notation, e.g. "0644".
+func formatFileMode(mode fs.FileMode) string {
+	return fmt.Sprintf("%04o", unixFileMode(mode))
+}
+
+func parseOctalFileMode(mode string) (fs.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || len(mode) > 4 {
+		return 0, fmt.Errorf("%q is not a valid octal file mode, e.g. '0644'", mode)
+	}
+	return fileModeFromUnix(uint32(value)), nil
+}
+
+func parseSymbolicFileMode(mode string, base fs.FileMode) (fs.FileMode, error) {
+	result := unixFileMode(base)
+
+	for _, clause := range strings.Split(mode, ",") {
+		// Each clause is [ugoa]*[=+-][rwxst]*
+		i := 0
+		var who uint32
+		for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) >= 0; i++ {
+			switch clause[i] {
+			case 'u':
+				who |= 04700
+			case 'g':
+				who |= 02070
+			case 'o':
+				who |= 01007
+			case 'a':
+				who |= 07777
+			}
+		}
+		if who == 0 {
+			who = 07777
+		}
+
+		if i >= len(clause) || strings.IndexByte("=+-", clause[i]) < 0 {
+			return 0, fmt.Errorf("%q is not a valid symbolic file mode, e.g. 'u=rw,go=r'", mode)
+		}
+		op := clause[i]
+
+		var perms uint32
+		for _, c := range clause[i+1:] {
+			switch c {
+			case 'r':
+				perms |= 0444
+			case 'w':
+				perms |= 0222
+			case 'x':
+				perms |= 0111
+			case 's':
+				perms |= 06000
+			case 't':
+				perms |= 01000
+			default:
+				return 0, fmt.Errorf("%q is not a valid symbolic file mode: unknown permission %q", mode, c)
+			}
+		}
+		perms &= who
+
+		switch op {
+		case '=':
+			result = result&^who | perms
+		case '+':
+			result |= perms
+		case '-':
+			result &^= perms
+		}
+	}
+
+	return fileModeFromUnix(result), nil
+}
+
+// fileModeFromUnix converts Unix permission bits, including setuid, setgid and
+// sticky, to an fs.FileMode.
+func fileModeFromUnix(mode uint32) fs.FileMode {
+	result := fs.FileMode(mode & 0777)
+	if mode&04000 != 0 {
+		result |= fs.ModeSetuid
+	}
+	if mode&02000 != 0 {
+		result |= fs.ModeSetgid
+	}
+	if mode&01000 != 0 {
+		result |= fs.ModeSticky
+	}
+	return result
+}
+
+// unixFileMode converts an fs.FileMode to Unix permission bits.
+func unixFileMode(mode fs.FileMode) uint32 {
+	result := uint32(mode.Perm())
+	if mode&fs.ModeSetuid != 0 {
+		result |= 04000
+	}
+	if mode&fs.ModeSetgid != 0 {
+		result |= 02000
+	}
+	if mode&fs.ModeSticky != 0 {
+		result |= 01000
+	}
+	return result
+}
+
+// fileModeBits returns the permission bits of a file mode, dropping the file type.
+func fileModeBits(mode fs.FileMode) fs.FileMode {
+	return mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &FileModeFunction{}
+
+func NewFileModeFunction() function.Function {
+	return &FileModeFunction{}
+}
+
+type FileModeFunction struct{}
+
+func (f *FileModeFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "file_mode"
+}
+
+func (f *FileModeFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Permissions of a file",
+		Description: "Returns the permissions of a file in octal notation, e.g. '0644', following symlinks. Relative paths are resolved against TF_LOCAL_BASE_DIR, or the directory Terraform is run from.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "path", Description: "Path to the file"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *FileModeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var path string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &path))
+	if resp.Error != nil {
+		return
+	}
+
+	resolved, err := functionPath(path)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Failed to access file: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, formatFileMode(fileModeBits(info.Mode()))))
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccFileModeFunction(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "test.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0750); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(baseDirEnvVar, tempDir)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "mode" {
+  value = provider::tf::file_mode("test.sh")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("mode", knownvalue.StringExact("0750")),
+				},
+			},
+			{
+				Config: `
+output "missing" {
+  value = provider::tf::file_mode("missing.sh")
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid value for "path" parameter`),
+			},
+		},
+	})
+}
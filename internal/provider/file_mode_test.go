@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected fs.FileMode
+	}{
+		{mode: "0644", expected: 0644},
+		{mode: "600", expected: 0600},
+		{mode: "0", expected: 0},
+		{mode: "4755", expected: 0755 | fs.ModeSetuid},
+		{mode: "1777", expected: 0777 | fs.ModeSticky},
+		{mode: "u=rw,go=", expected: 0600},
+		{mode: "u=rw,go=r", expected: 0644},
+		{mode: "a=r,u+w", expected: 0644},
+		{mode: "a=rwx,o-rwx", expected: 0770},
+		{mode: "=rx,u+w", expected: 0755},
+		{mode: "u=rwxs,g=rx,o=", expected: 0750 | fs.ModeSetuid},
+		{mode: "a=rwxt", expected: 0777 | fs.ModeSticky},
+		// Relative clauses modify the default file mode
+		{mode: "go-w", expected: 0644},
+		{mode: "+x", expected: 0777},
+		{mode: "a=", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			mode, err := parseFileMode(tt.mode, defaultFileMode)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, mode)
+			}
+		})
+	}
+
+	// Directories start from the default directory mode
+	mode, err := parseFileMode("go-w", defaultDirectoryMode)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mode != 0755 {
+		t.Errorf("expected %v, got %v", fs.FileMode(0755), mode)
+	}
+}
+
+func TestParseFileMode_Invalid(t *testing.T) {
+	for _, mode := range []string{"", "0844", "9", "07777777", "rw", "u=rwz", "u", "0644 "} {
+		t.Run(mode, func(t *testing.T) {
+			if _, err := parseFileMode(mode, defaultFileMode); err == nil {
+				t.Errorf("expected an error for %q", mode)
+			}
+		})
+	}
+}
+
+func TestFormatFileMode(t *testing.T) {
+	tests := []struct {
+		mode     fs.FileMode
+		expected string
+	}{
+		{mode: 0644, expected: "0644"},
+		{mode: 0600, expected: "0600"},
+		{mode: 0755 | fs.ModeSetgid, expected: "2755"},
+	}
+
+	for _, tt := range tests {
+		if actual := formatFileMode(tt.mode); actual != tt.expected {
+			t.Errorf("expected %s, got %s", tt.expected, actual)
+		}
+	}
+}
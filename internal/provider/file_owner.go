@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// fileOwnership is the owner and group to apply to a file. A value of -1
+// leaves the corresponding ID unchanged, as with os.Chown.
+type fileOwnership struct {
+	UID int
+	GID int
+}
+
+// unchangedOwnership leaves both the owner and the group of a file unchanged.
+var unchangedOwnership = fileOwnership{UID: -1, GID: -1}
+
+func (o fileOwnership) changes() bool {
+	return o.UID != -1 || o.GID != -1
+}
+
+func (o fileOwnership) String() string {
+	owner, group := "-", "-"
+	if o.UID != -1 {
+		owner = formatOwner(o.UID)
+	}
+	if o.GID != -1 {
+		group = formatGroup(o.GID)
+	}
+	return owner + ":" + group
+}
+
+// ownershipError is returned when the ownership of a file cannot be changed.
+type ownershipError struct {
+	Path      string
+	Ownership fileOwnership
+	Err       error
+}
+
+func (e *ownershipError) Error() string {
+	if errors.Is(e.Err, fs.ErrPermission) {
+		return fmt.Sprintf("insufficient privileges to change the ownership of %s to %s. Changing the owner of a file requires running Terraform as root or with the CAP_CHOWN capability, and changing only the group requires the provider's user to be a member of that group.", e.Path, e.Ownership)
+	}
+	return fmt.Sprintf("failed to change the ownership of %s to %s: %v", e.Path, e.Ownership, e.Err)
+}
+
+func (e *ownershipError) Unwrap() error {
+	return e.Err
+}
+
+// lookupOwner resolves a user name or numeric user ID to a user ID.
+func lookupOwner(owner string) (int, error) {
+	if uid, err := strconv.Atoi(owner); err == nil && uid >= 0 {
+		return uid, nil
+	}
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return 0, fmt.Errorf("unknown user %q", owner)
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGroup resolves a group name or numeric group ID to a group ID.
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil && gid >= 0 {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %q", group)
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// formatOwner returns the name of a user ID, or the numeric ID if it has no name.
+func formatOwner(uid int) string {
+	if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+		return u.Username
+	}
+	return strconv.Itoa(uid)
+}
+
+// formatGroup returns the name of a group ID, or the numeric ID if it has no name.
+func formatGroup(gid int) string {
+	if g, err := user.LookupGroupId(strconv.Itoa(gid)); err == nil {
+		return g.Name
+	}
+	return strconv.Itoa(gid)
+}
+
+// fileOwnershipOf returns the owner and group of a file.
+func fileOwnershipOf(info fs.FileInfo) (fileOwnership, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return unchangedOwnership, fmt.Errorf("file ownership is not supported on this platform")
+	}
+	return fileOwnership{UID: int(stat.Uid), GID: int(stat.Gid)}, nil
+}
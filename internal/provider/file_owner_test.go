@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestLookupOwner(t *testing.T) {
+	tests := []struct {
+		owner    string
+		expected int
+	}{
+		{owner: "root", expected: 0},
+		{owner: "0", expected: 0},
+		// Numeric IDs don't need to exist
+		{owner: "4242", expected: 4242},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.owner, func(t *testing.T) {
+			uid, err := lookupOwner(tt.owner)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if uid != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, uid)
+			}
+		})
+	}
+
+	if _, err := lookupOwner("no-such-user"); err == nil || !strings.Contains(err.Error(), `unknown user "no-such-user"`) {
+		t.Errorf("expected unknown user error, got %v", err)
+	}
+}
+
+func TestLookupGroup(t *testing.T) {
+	gid, err := lookupGroup("0")
+	if err != nil || gid != 0 {
+		t.Errorf("expected 0, got %d (%v)", gid, err)
+	}
+	if _, err := lookupGroup("no-such-group"); err == nil || !strings.Contains(err.Error(), `unknown group "no-such-group"`) {
+		t.Errorf("expected unknown group error, got %v", err)
+	}
+}
+
+func TestOwnershipError(t *testing.T) {
+	err := &ownershipError{Path: "/etc/app.conf", Ownership: fileOwnership{UID: 4242, GID: -1}, Err: fs.ErrPermission}
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected error to wrap fs.ErrPermission")
+	}
+	if !strings.Contains(err.Error(), "insufficient privileges to change the ownership of /etc/app.conf to 4242:-") {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &GlobFunction{}
+
+func NewGlobFunction() function.Function {
+	return &GlobFunction{}
+}
+
+type GlobFunction struct{}
+
+func (f *GlobFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "glob"
+}
+
+func (f *GlobFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Paths matching a glob pattern",
+		Description: "Returns the files and directories matching a glob pattern, sorted. * and ? match within a path element and ** matches any number of directories. Relative patterns are resolved against TF_LOCAL_BASE_DIR, or the directory Terraform is run from, and return relative paths.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "pattern", Description: "Glob pattern, e.g. 'config/**/*.yaml'"},
+		},
+		Return: function.ListReturn{ElementType: types.StringType},
+	}
+}
+
+func (f *GlobFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var pattern string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &pattern))
+	if resp.Error != nil {
+		return
+	}
+
+	baseDir, err := functionBaseDir()
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	relative := baseDir != "" && !filepath.IsAbs(pattern)
+	if relative {
+		pattern = filepath.Join(baseDir, pattern)
+	}
+
+	matches, err := globPaths(pattern)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid pattern: %v", err))
+		return
+	}
+	if relative {
+		for i, match := range matches {
+			if matches[i], err = filepath.Rel(baseDir, match); err != nil {
+				resp.Error = function.NewFuncError(err.Error())
+				return
+			}
+		}
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, matches))
+}
+
+// globPaths returns the paths matching pattern in lexical order. Unlike
+// filepath.Glob, ** matches any number of directories.
+func globPaths(pattern string) ([]string, error) {
+	matches := []string{}
+
+	if !strings.Contains(pattern, "**") {
+		found, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return append(matches, found...), nil
+	}
+
+	// Walked paths are clean, so the pattern needs to be as well
+	pattern = filepath.Clean(pattern)
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := splitGlobPattern(pattern)
+	if root == "" {
+		root = "."
+	}
+	err = filepath.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			// A missing root matches nothing
+			if path == root && os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if re.MatchString(path) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
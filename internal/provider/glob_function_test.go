@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestGlobPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"a.yaml", "b.json", "config/c.yaml", "config/nested/d.yaml"} {
+		path := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "*.yaml", expected: []string{"a.yaml"}},
+		{pattern: "config/*", expected: []string{"config/c.yaml", "config/nested"}},
+		{pattern: "**/*.yaml", expected: []string{"a.yaml", "config/c.yaml", "config/nested/d.yaml"}},
+		{pattern: "config/**/*.yaml", expected: []string{"config/c.yaml", "config/nested/d.yaml"}},
+		{pattern: "missing/**", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := globPaths(filepath.Join(tempDir, tt.pattern))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := make([]string, len(tt.expected))
+			for i, path := range tt.expected {
+				expected[i] = filepath.Join(tempDir, path)
+			}
+			if !reflect.DeepEqual(matches, expected) {
+				t.Errorf("expected %v, got %v", expected, matches)
+			}
+		})
+	}
+
+	if _, err := globPaths("[a"); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
+
+func TestAccGlobFunction(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, file := range []string{"a.yaml", "b.json", "config/c.yaml"} {
+		path := filepath.Join(tempDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(baseDirEnvVar, tempDir)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "matches" {
+  value = provider::tf::glob("**/*.yaml")
+}
+
+output "none" {
+  value = provider::tf::glob("*.toml")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("matches", knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.StringExact("a.yaml"),
+						knownvalue.StringExact("config/c.yaml"),
+					})),
+					statecheck.ExpectKnownOutputValue("none", knownvalue.ListSizeExact(0)),
+				},
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::tf::glob("[a")
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid value for "pattern" parameter`),
+			},
+		},
+	})
+}
@@ -2,39 +2,92 @@ package provider
 
 import (
 	"context"
-	"time"
+	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type LocalExecDataSourceModel struct {
-	Command       types.String `tfsdk:"command"`
-	Output        types.String `tfsdk:"output"`
-	ExitCode      types.Int64  `tfsdk:"exit_code"`
-	FailIfNonzero types.Bool   `tfsdk:"fail_if_nonzero"`
-	Id            types.String `tfsdk:"id"`
+	Command                types.String         `tfsdk:"command"`
+	Interpreter            types.List           `tfsdk:"interpreter"`
+	Argv                   types.List           `tfsdk:"argv"`
+	Environment            types.Map            `tfsdk:"environment"`
+	SensitiveEnvironment   types.Map            `tfsdk:"sensitive_environment"`
+	InheritEnvironment     types.Bool           `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List           `tfsdk:"environment_passthrough"`
+	WorkingDir             types.String         `tfsdk:"working_dir"`
+	Stdin                  types.String         `tfsdk:"stdin"`
+	SensitiveStdin         types.String         `tfsdk:"sensitive_stdin"`
+	Timeout                types.String         `tfsdk:"timeout"`
+	GracePeriod            types.String         `tfsdk:"grace_period"`
+	Retry                  *LocalExecRetryModel `tfsdk:"retry"`
+	Attempts               types.Int64          `tfsdk:"attempts"`
+	OutputFormat           types.String         `tfsdk:"output_format"`
+	Output                 types.String         `tfsdk:"output"`
+	Stdout                 types.String         `tfsdk:"stdout"`
+	Stderr                 types.String         `tfsdk:"stderr"`
+	Result                 types.Dynamic        `tfsdk:"result"`
+	ExitCode               types.Int64          `tfsdk:"exit_code"`
+	FailIfNonzero          types.Bool           `tfsdk:"fail_if_nonzero"`
+	Id                     types.String         `tfsdk:"id"`
+	Timeouts               timeouts.Value       `tfsdk:"timeouts"`
 }
 
 var LocalExecDataSourceSchema = schema.Schema{
 	Description: "Execute local commands",
 	Attributes: map[string]schema.Attribute{
-		"command":         schema.StringAttribute{Required: true, Description: "Command to execute"},
-		"output":          schema.StringAttribute{Computed: true, Description: "Output of the command"},
-		"exit_code":       schema.Int64Attribute{Computed: true, Description: "Exit code of the command"},
-		"fail_if_nonzero": schema.BoolAttribute{Optional: true, Description: "Whether to fail if the command returns a non-zero exit code"},
-		"id":              schema.StringAttribute{Computed: true, Description: "Unique identifier for this execution"},
+		"command":                 schema.StringAttribute{Optional: true, Description: "Command to execute. Exactly one of command or argv must be set."},
+		"interpreter":             schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Interpreter and arguments used to run command, e.g. [\"python3\", \"-c\"]. The command is appended as the last argument. Defaults to [\"sh\", \"-c\"]."},
+		"argv":                    schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Program and arguments to execute directly, without a shell. Exactly one of command or argv must be set."},
+		"environment":             schema.MapAttribute{ElementType: types.StringType, Optional: true, Description: "Additional environment variables for the command"},
+		"sensitive_environment":   schema.MapAttribute{ElementType: types.StringType, Optional: true, Sensitive: true, Description: "Additional environment variables for the command, hidden from plan output. Takes precedence over environment."},
+		"inherit_environment":     schema.BoolAttribute{Optional: true, Description: "Whether the command inherits the provider's environment. When false, the command starts from an empty environment plus environment_passthrough. Defaults to true."},
+		"environment_passthrough": schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Names of provider environment variables passed to the command when inherit_environment is false"},
+		"working_dir":             schema.StringAttribute{Optional: true, Description: "Directory to run the command in. Relative paths are resolved against the provider base_dir. Must exist when the command runs."},
+		"stdin":                   schema.StringAttribute{Optional: true, Description: "Data written to the command's standard input"},
+		"sensitive_stdin":         schema.StringAttribute{Optional: true, Sensitive: true, Description: "Data written to the command's standard input, hidden from plan output. Conflicts with stdin."},
+		"timeout":                 schema.StringAttribute{Optional: true, Description: "Maximum duration of the command run, e.g. '30s' or '5m'. No limit if not specified."},
+		"grace_period":            schema.StringAttribute{Optional: true, Description: "How long to wait after sending SIGTERM to the command's process group on timeout or cancellation before sending SIGKILL, and how long to wait for the output of descendants that outlive the command, e.g. daemons. Defaults to 10s."},
+		"output_format":           schema.StringAttribute{Optional: true, Description: "Format of the command's stdout: 'text', 'json' or 'yaml'. YAML output must be a single document. Parsed output is available in result. Defaults to 'text'."},
+		"output":                  schema.StringAttribute{Computed: true, Description: "Combined stdout and stderr of the command, in the order it was written"},
+		"stdout":                  schema.StringAttribute{Computed: true, Description: "Standard output of the command"},
+		"stderr":                  schema.StringAttribute{Computed: true, Description: "Standard error of the command"},
+		"result":                  schema.DynamicAttribute{Computed: true, Description: "Parsed stdout when output_format is 'json' or 'yaml' and the command succeeded, otherwise null"},
+		"exit_code":               schema.Int64Attribute{Computed: true, Description: "Exit code of the command"},
+		"attempts":                schema.Int64Attribute{Computed: true, Description: "Number of times the command was run, including retries"},
+		"fail_if_nonzero":         schema.BoolAttribute{Optional: true, Description: "Whether to fail if the command returns a non-zero exit code"},
+		"id":                      schema.StringAttribute{Computed: true, Description: "Hex-encoded SHA256 of the command line, i.e. the interpreter and command or argv, and of environment, after the provider's default_interpreter and default_environment are applied. Sensitive environment variables are not part of the ID."},
+	},
+	Blocks: map[string]schema.Block{
+		"retry": schema.SingleNestedBlock{
+			Description: "Retry the command when it exits with a non-zero exit code",
+			Attributes: map[string]schema.Attribute{
+				"max_attempts":        schema.Int64Attribute{Optional: true, Description: "Maximum number of times to run the command, including the first run. Defaults to 3."},
+				"initial_delay":       schema.StringAttribute{Optional: true, Description: "Delay before the first retry, e.g. '1s'. Defaults to 1s."},
+				"max_delay":           schema.StringAttribute{Optional: true, Description: "Upper bound for the delay between retries. Defaults to 30s."},
+				"multiplier":          schema.Float64Attribute{Optional: true, Description: "Factor the delay is multiplied by after each retry. Defaults to 2."},
+				"retry_on_exit_codes": schema.ListAttribute{ElementType: types.Int64Type, Optional: true, Description: "Exit codes that trigger a retry. Defaults to any non-zero exit code."},
+			},
+		},
+		"timeouts": timeouts.Block(context.Background()),
 	},
 }
 
 var _ datasource.DataSource = &LocalExecDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &LocalExecDataSource{}
 
 func NewLocalExecDataSource() datasource.DataSource {
 	return &LocalExecDataSource{}
 }
 
-type LocalExecDataSource struct{}
+type LocalExecDataSource struct {
+	config *localProviderConfig
+}
 
 func (d *LocalExecDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_local_exec"
@@ -44,8 +97,40 @@ func (d *LocalExecDataSource) Schema(_ context.Context, _ datasource.SchemaReque
 	resp.Schema = LocalExecDataSourceSchema
 }
 
+func (d *LocalExecDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data LocalExecDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateLocalCommandConfig(data.Command, data.Interpreter, data.Argv)...)
+	_, diags := parseDuration(data.Timeout, path.Root("timeout"))
+	resp.Diagnostics.Append(diags...)
+	_, diags = parseDuration(data.GracePeriod, path.Root("grace_period"))
+	resp.Diagnostics.Append(diags...)
+	_, diags = data.Retry.retryPolicy(ctx)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(validateOutputFormat(data.OutputFormat)...)
+
+	if !data.Stdin.IsNull() && !data.SensitiveStdin.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("sensitive_stdin"), "Conflicting attributes", "Only one of stdin or sensitive_stdin can be set.")
+	}
+}
+
 func (d *LocalExecDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
-	// No configuration needed
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*localProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *localProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+	d.config = config
 }
 
 func (d *LocalExecDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -58,21 +143,96 @@ func (d *LocalExecDataSource) Read(ctx context.Context, req datasource.ReadReque
 
 	// Set default value for fail_if_nonzero if not specified
 	if data.FailIfNonzero.IsNull() {
-		data.FailIfNonzero = types.BoolValue(true)
+		data.FailIfNonzero = types.BoolValue(d.config.failIfNonzero())
 	}
 
-	// Generate ID early, based on the command
-	data.Id = types.StringValue(generateExecID(data.Command.ValueString(), time.Now()))
+	command, diags := data.localCommand(ctx, d.config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Data sources are read during plan, so this fails the plan before anything runs
+	if err := d.config.checkCommand(command); err != nil {
+		resp.Diagnostics.AddError("Command not allowed", err.Error())
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := contextWithOptionalTimeout(ctx, readTimeout)
+	defer cancel()
+
+	data.Id, diags = localExecID(ctx, d.config, data.Command, data.Argv, data.Interpreter, data.Environment)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Execute the command
-	output, exitCode, err := executeLocalCommand(data.Command.ValueString(), data.FailIfNonzero.ValueBool())
+	result, err := executeLocalCommand(ctx, command)
 	if err != nil {
 		resp.Diagnostics.AddError("Command execution failed", err.Error())
 		return
 	}
 
-	data.Output = types.StringValue(output)
-	data.ExitCode = types.Int64Value(exitCode)
+	data.Output = types.StringValue(result.Output)
+	data.Stdout = types.StringValue(result.Stdout)
+	data.Stderr = types.StringValue(result.Stderr)
+	data.ExitCode = types.Int64Value(result.ExitCode)
+	data.Attempts = types.Int64Value(result.Attempts)
+
+	// Parse the output according to output_format
+	data.Result, diags = commandResultValue(ctx, data.OutputFormat, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+func (m *LocalExecDataSourceModel) localCommand(ctx context.Context, config *localProviderConfig) (localCommand, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	interpreter, d := listValueToStrings(ctx, m.Interpreter)
+	diags.Append(d...)
+	argv, d := listValueToStrings(ctx, m.Argv)
+	diags.Append(d...)
+	environment, d := mergedEnvironment(ctx, m.Environment, m.SensitiveEnvironment)
+	diags.Append(d...)
+	passthrough, d := listValueToStrings(ctx, m.EnvironmentPassthrough)
+	diags.Append(d...)
+	timeout, d := parseDuration(m.Timeout, path.Root("timeout"))
+	diags.Append(d...)
+	gracePeriod, d := parseDuration(m.GracePeriod, path.Root("grace_period"))
+	diags.Append(d...)
+	retry, d := m.Retry.retryPolicy(ctx)
+	diags.Append(d...)
+
+	stdin := m.Stdin.ValueString()
+	if !m.SensitiveStdin.IsNull() {
+		stdin = m.SensitiveStdin.ValueString()
+	}
+
+	command := localCommand{
+		Command:                m.Command.ValueString(),
+		Interpreter:            interpreter,
+		Argv:                   argv,
+		Environment:            environment,
+		InheritEnvironment:     m.InheritEnvironment.IsNull() || m.InheritEnvironment.ValueBool(),
+		EnvironmentPassthrough: passthrough,
+		WorkingDir:             config.resolvePath(m.WorkingDir.ValueString()),
+		Stdin:                  stdin,
+		Timeout:                timeout,
+		GracePeriod:            gracePeriod,
+		Retry:                  retry,
+		FailIfNonzero:          m.FailIfNonzero.ValueBool(),
+	}
+	config.applyDefaults(&command)
+
+	return command, diags
+}
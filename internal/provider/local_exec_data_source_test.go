@@ -37,6 +37,33 @@ func TestAccLocalExecDataSource(t *testing.T) {
 					// Script command
 					resource.TestCheckResourceAttr("data.tf_local_exec.script", "exit_code", "0"),
 					resource.TestCheckResourceAttr("data.tf_local_exec.script", "output", "Hello\n"),
+
+					// Separate stdout and stderr
+					resource.TestCheckResourceAttr("data.tf_local_exec.streams", "stdout", "out 1\nout 2\n"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.streams", "stderr", "err 1\n"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.streams", "output", "out 1\nerr 1\nout 2\n"),
+
+					// Custom interpreter
+					resource.TestCheckResourceAttr("data.tf_local_exec.python", "output", "3\n"),
+
+					// Direct argv execution without a shell
+					resource.TestCheckResourceAttr("data.tf_local_exec.argv", "output", "$HOME 'quoted'\n"),
+
+					// Environment variables
+					resource.TestCheckResourceAttr("data.tf_local_exec.environment", "output", "visible secret\n"),
+
+					// Isolated environment with passthrough
+					resource.TestCheckResourceAttr("data.tf_local_exec.isolated", "output", "HOME=set\nTF_ACC=\nFOO=bar\n"),
+
+					// Standard input
+					resource.TestCheckResourceAttr("data.tf_local_exec.stdin", "output", "LINE 1\nLINE 2\n"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.sensitive_stdin", "output", "6\n"),
+
+					// Structured output
+					resource.TestCheckResourceAttr("data.tf_local_exec.json", "result.name", "app"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.json", "result.ports.1", "443"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.yaml", "result.nested.enabled", "true"),
+					resource.TestCheckNoResourceAttr("data.tf_local_exec.basic", "result"),
 				),
 			},
 		},
@@ -68,6 +95,52 @@ data "tf_local_exec" "multiline" {
 data "tf_local_exec" "script" {
   command = "echo Hello"
 }
+
+data "tf_local_exec" "streams" {
+  command = "echo 'out 1'; sleep 0.1; echo 'err 1' >&2; sleep 0.1; echo 'out 2'"
+}
+
+data "tf_local_exec" "python" {
+  interpreter = ["python3", "-c"]
+  command     = "print(1 + 2)"
+}
+
+data "tf_local_exec" "argv" {
+  argv = ["echo", "$HOME", "'quoted'"]
+}
+
+data "tf_local_exec" "environment" {
+  command               = "echo \"$PUBLIC $SECRET\""
+  environment           = { PUBLIC = "visible", SECRET = "overridden" }
+  sensitive_environment = { SECRET = "secret" }
+}
+
+data "tf_local_exec" "isolated" {
+  command                 = "echo HOME=$${HOME:+set}; echo TF_ACC=$TF_ACC; echo FOO=$FOO"
+  inherit_environment     = false
+  environment_passthrough = ["HOME"]
+  environment             = { FOO = "bar" }
+}
+
+data "tf_local_exec" "stdin" {
+  command = "tr a-z A-Z"
+  stdin   = "line 1\nline 2\n"
+}
+
+data "tf_local_exec" "json" {
+  command       = "echo progress >&2; echo '{\"name\": \"app\", \"ports\": [80, 443]}'"
+  output_format = "json"
+}
+
+data "tf_local_exec" "yaml" {
+  command       = "printf 'nested:\\n  enabled: true\\n'"
+  output_format = "yaml"
+}
+
+data "tf_local_exec" "sensitive_stdin" {
+  command         = "wc -c | tr -d ' '"
+  sensitive_stdin = "secret"
+}
 `
 }
 
@@ -93,3 +166,93 @@ data "tf_local_exec" "nonzero_fail" {
 }
 `
 }
+
+// Test for expected failure when both command and argv are set
+func TestAccLocalExecDataSource_CommandAndArgv(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tf_local_exec" "conflict" {
+  command = "echo hi"
+  argv    = ["echo", "hi"]
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of command or argv must be set`),
+			},
+		},
+	})
+}
+
+// Test for expected failure when the command exceeds its timeout
+func TestAccLocalExecDataSource_Timeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tf_local_exec" "slow" {
+  command      = "sleep 30"
+  timeout      = "1s"
+  grace_period = "1s"
+}
+`,
+				ExpectError: regexp.MustCompile(`command timed out`),
+			},
+		},
+	})
+}
+
+// Test for expected failure when the command keeps failing after all retries
+func TestAccLocalExecDataSource_RetryExhausted(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tf_local_exec" "always_fails" {
+  command = "exit 1"
+
+  retry {
+    max_attempts  = 2
+    initial_delay = "100ms"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`command exited with code 1 after 2 attempts`),
+			},
+		},
+	})
+}
+
+// Test for expected failure when the output does not match output_format
+func TestAccLocalExecDataSource_InvalidOutput(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "tf_local_exec" "invalid_json" {
+  command       = "echo '{\"name\": }'"
+  output_format = "json"
+}
+`,
+				ExpectError: regexp.MustCompile(`stdout is not valid JSON at byte offset 10`),
+			},
+			{
+				Config: `
+data "tf_local_exec" "invalid_format" {
+  command       = "echo hi"
+  output_format = "toml"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid output format`),
+			},
+		},
+	})
+}
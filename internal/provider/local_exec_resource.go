@@ -1,45 +1,124 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/dynamicplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type LocalExecResourceModel struct {
-	Command       types.String `tfsdk:"command"`
-	Output        types.String `tfsdk:"output"`
-	ExitCode      types.Int64  `tfsdk:"exit_code"`
-	FailIfNonzero types.Bool   `tfsdk:"fail_if_nonzero"`
-	OnDestroy     types.String `tfsdk:"on_destroy"`
-	Id            types.String `tfsdk:"id"`
+	Command                types.String         `tfsdk:"command"`
+	Interpreter            types.List           `tfsdk:"interpreter"`
+	Argv                   types.List           `tfsdk:"argv"`
+	Environment            types.Map            `tfsdk:"environment"`
+	SensitiveEnvironment   types.Map            `tfsdk:"sensitive_environment"`
+	InheritEnvironment     types.Bool           `tfsdk:"inherit_environment"`
+	EnvironmentPassthrough types.List           `tfsdk:"environment_passthrough"`
+	WorkingDir             types.String         `tfsdk:"working_dir"`
+	Stdin                  types.String         `tfsdk:"stdin"`
+	SensitiveStdin         types.String         `tfsdk:"sensitive_stdin"`
+	Timeout                types.String         `tfsdk:"timeout"`
+	GracePeriod            types.String         `tfsdk:"grace_period"`
+	Retry                  *LocalExecRetryModel `tfsdk:"retry"`
+	Attempts               types.Int64          `tfsdk:"attempts"`
+	OutputFormat           types.String         `tfsdk:"output_format"`
+	Output                 types.String         `tfsdk:"output"`
+	Stdout                 types.String         `tfsdk:"stdout"`
+	Stderr                 types.String         `tfsdk:"stderr"`
+	Result                 types.Dynamic        `tfsdk:"result"`
+	ExitCode               types.Int64          `tfsdk:"exit_code"`
+	FailIfNonzero          types.Bool           `tfsdk:"fail_if_nonzero"`
+	OnDestroy              types.String         `tfsdk:"on_destroy"`
+	CheckCommand           types.String         `tfsdk:"check_command"`
+	OnDrift                types.String         `tfsdk:"on_drift"`
+	Drifted                types.Bool           `tfsdk:"drifted"`
+	Triggers               types.Map            `tfsdk:"triggers"`
+	TriggersReplace        types.Dynamic        `tfsdk:"triggers_replace"`
+	Id                     types.String         `tfsdk:"id"`
+	Timeouts               timeouts.Value       `tfsdk:"timeouts"`
 }
 
 var LocalExecResourceSchema = schema.Schema{
 	Description: "Execute local commands with potential side effects",
+	// Version 1 replaced timestamp-based IDs with deterministic ones
+	Version: 1,
 	Attributes: map[string]schema.Attribute{
-		"command":         schema.StringAttribute{Required: true, Description: "Command to execute"},
-		"output":          schema.StringAttribute{Computed: true, Description: "Output of the command"},
-		"exit_code":       schema.Int64Attribute{Computed: true, Description: "Exit code of the command"},
-		"fail_if_nonzero": schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether to fail if the command returns a non-zero exit code. Defaults to true if not specified."},
-		"on_destroy":      schema.StringAttribute{Optional: true, Description: "Command to execute when the resource is destroyed"},
-		"id":              schema.StringAttribute{Computed: true, Description: "Unique identifier for this execution"},
+		"command":                 schema.StringAttribute{Optional: true, Description: "Command to execute. Exactly one of command or argv must be set."},
+		"interpreter":             schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Interpreter and arguments used to run command and on_destroy, e.g. [\"bash\", \"-euo\", \"pipefail\", \"-c\"]. The command is appended as the last argument. Defaults to [\"sh\", \"-c\"]."},
+		"argv":                    schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Program and arguments to execute directly, without a shell. Exactly one of command or argv must be set."},
+		"environment":             schema.MapAttribute{ElementType: types.StringType, Optional: true, Description: "Additional environment variables for the command"},
+		"sensitive_environment":   schema.MapAttribute{ElementType: types.StringType, Optional: true, Sensitive: true, Description: "Additional environment variables for the command, hidden from plan output. Takes precedence over environment."},
+		"inherit_environment":     schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether the command inherits the provider's environment. When false, the command starts from an empty environment plus environment_passthrough. Defaults to true."},
+		"environment_passthrough": schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Names of provider environment variables passed to the command when inherit_environment is false"},
+		"working_dir":             schema.StringAttribute{Optional: true, Description: "Directory to run the command in. Relative paths are resolved against the provider base_dir. Must exist when the command runs."},
+		"stdin":                   schema.StringAttribute{Optional: true, Description: "Data written to the command's standard input"},
+		"sensitive_stdin":         schema.StringAttribute{Optional: true, Sensitive: true, Description: "Data written to the command's standard input, hidden from plan output. Conflicts with stdin."},
+		"timeout":                 schema.StringAttribute{Optional: true, Description: "Maximum duration of a single command run, e.g. '30s' or '5m'. No limit if not specified."},
+		"grace_period":            schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString(defaultGracePeriod.String()), Description: "How long to wait after sending SIGTERM to the command's process group on timeout or cancellation before sending SIGKILL, and how long to wait for the output of descendants that outlive the command, e.g. daemons. Defaults to 10s."},
+		"output_format":           schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString(outputFormatText), Description: "Format of the command's stdout: 'text', 'json' or 'yaml'. YAML output must be a single document. Parsed output is available in result. Defaults to 'text'."},
+		"output":                  schema.StringAttribute{Computed: true, Description: "Combined stdout and stderr of the command, in the order it was written"},
+		"stdout":                  schema.StringAttribute{Computed: true, Description: "Standard output of the command"},
+		"stderr":                  schema.StringAttribute{Computed: true, Description: "Standard error of the command"},
+		"result":                  schema.DynamicAttribute{Computed: true, Description: "Parsed stdout when output_format is 'json' or 'yaml' and the command succeeded, otherwise null"},
+		"exit_code":               schema.Int64Attribute{Computed: true, Description: "Exit code of the command"},
+		"attempts":                schema.Int64Attribute{Computed: true, Description: "Number of times the command was run, including retries"},
+		"fail_if_nonzero":         schema.BoolAttribute{Optional: true, Computed: true, Description: "Whether to fail if the command returns a non-zero exit code. Defaults to the provider's default_fail_if_nonzero at the time the resource is created, which defaults to true."},
+		"on_destroy":              schema.StringAttribute{Optional: true, Description: "Command to execute when the resource is destroyed"},
+		"check_command":           schema.StringAttribute{Optional: true, Description: "Command executed during refresh to check that the command's side effects are still in place. A non-zero exit code means the resource has drifted."},
+		"on_drift":                schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString(onDriftRecreate), Description: "What to do when check_command reports drift: 'recreate' removes the resource from state so that the command runs again, 'report' only sets drifted. Defaults to 'recreate'."},
+		"drifted":                 schema.BoolAttribute{Computed: true, Description: "Whether check_command reported drift during the last refresh"},
+		"triggers":                schema.MapAttribute{ElementType: types.StringType, Optional: true, Description: "Arbitrary values that re-run the command in place when changed"},
+		"triggers_replace":        schema.DynamicAttribute{Optional: true, PlanModifiers: []planmodifier.Dynamic{dynamicplanmodifier.RequiresReplace()}, Description: "Arbitrary value that replaces the resource when changed, running on_destroy before the command is run again"},
+		"id":                      schema.StringAttribute{Computed: true, Description: "Hex-encoded SHA256 of the command line, i.e. the interpreter and command or argv, and of environment, after the provider's default_interpreter and default_environment are applied. Sensitive environment variables are not part of the ID."},
+	},
+	Blocks: map[string]schema.Block{
+		"retry": schema.SingleNestedBlock{
+			Description: "Retry the command when it exits with a non-zero exit code",
+			Attributes: map[string]schema.Attribute{
+				"max_attempts":        schema.Int64Attribute{Optional: true, Description: "Maximum number of times to run the command, including the first run. Defaults to 3."},
+				"initial_delay":       schema.StringAttribute{Optional: true, Description: "Delay before the first retry, e.g. '1s'. Defaults to 1s."},
+				"max_delay":           schema.StringAttribute{Optional: true, Description: "Upper bound for the delay between retries. Defaults to 30s."},
+				"multiplier":          schema.Float64Attribute{Optional: true, Description: "Factor the delay is multiplied by after each retry. Defaults to 2."},
+				"retry_on_exit_codes": schema.ListAttribute{ElementType: types.Int64Type, Optional: true, Description: "Exit codes that trigger a retry. Defaults to any non-zero exit code."},
+			},
+		},
+		"timeouts": timeouts.Block(context.Background(), timeouts.Opts{Create: true, Read: true, Update: true, Delete: true}),
 	},
 }
 
 var _ resource.Resource = &LocalExecResource{}
+var _ resource.ResourceWithValidateConfig = &LocalExecResource{}
+var _ resource.ResourceWithModifyPlan = &LocalExecResource{}
+var _ resource.ResourceWithImportState = &LocalExecResource{}
+var _ resource.ResourceWithUpgradeState = &LocalExecResource{}
 
 func NewLocalExecResource() resource.Resource {
 	return &LocalExecResource{}
 }
 
-type LocalExecResource struct{}
+type LocalExecResource struct {
+	config *localProviderConfig
+}
 
 func (r *LocalExecResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_local_exec"
@@ -49,8 +128,153 @@ func (r *LocalExecResource) Schema(_ context.Context, _ resource.SchemaRequest,
 	resp.Schema = LocalExecResourceSchema
 }
 
+func (r *LocalExecResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data LocalExecResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateLocalCommandConfig(data.Command, data.Interpreter, data.Argv)...)
+	_, diags := parseDuration(data.Timeout, path.Root("timeout"))
+	resp.Diagnostics.Append(diags...)
+	_, diags = parseDuration(data.GracePeriod, path.Root("grace_period"))
+	resp.Diagnostics.Append(diags...)
+	_, diags = data.Retry.retryPolicy(ctx)
+	resp.Diagnostics.Append(diags...)
+
+	resp.Diagnostics.Append(validateOutputFormat(data.OutputFormat)...)
+
+	if !data.OnDrift.IsNull() && !data.OnDrift.IsUnknown() && data.OnDrift.ValueString() != onDriftRecreate && data.OnDrift.ValueString() != onDriftReport {
+		resp.Diagnostics.AddAttributeError(path.Root("on_drift"), "Invalid on_drift", fmt.Sprintf("on_drift must be one of %s, %s, got %q.", onDriftRecreate, onDriftReport, data.OnDrift.ValueString()))
+	}
+
+	if !data.Stdin.IsNull() && !data.SensitiveStdin.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("sensitive_stdin"), "Conflicting attributes", "Only one of stdin or sensitive_stdin can be set.")
+	}
+}
+
+func (r *LocalExecResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Only on_destroy runs when the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		var state LocalExecResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Command = types.StringNull()
+		state.Argv = types.ListNull(types.StringType)
+		state.CheckCommand = types.StringNull()
+		resp.Diagnostics.Append(r.checkExecPolicy(ctx, state)...)
+		return
+	}
+
+	var workingDir types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("working_dir"), &workingDir)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Validate the working directory when it is known at plan time
+	if !workingDir.IsNull() && !workingDir.IsUnknown() {
+		if err := checkWorkingDir(r.config.resolvePath(workingDir.ValueString())); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("working_dir"), "Invalid working directory", err.Error())
+		}
+	}
+
+	// The ID is derived from the command, so it changes along with it
+	var data LocalExecResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id, diags := localExecID(ctx, r.config, data.Command, data.Argv, data.Interpreter, data.Environment)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), id)...)
+
+	// Apply the provider's default_fail_if_nonzero when fail_if_nonzero is not
+	// set. Existing resources keep their value, so that changing the default
+	// does not run every command again.
+	var failIfNonzero types.Bool
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("fail_if_nonzero"), &failIfNonzero)...)
+	if failIfNonzero.IsNull() {
+		var prior types.Bool
+		if !req.State.Raw.IsNull() {
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("fail_if_nonzero"), &prior)...)
+		}
+		if prior.IsNull() {
+			prior = types.BoolValue(r.config.failIfNonzero())
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fail_if_nonzero"), prior)...)
+	}
+
+	// Check the exec policy at plan time, before anything runs. Nothing runs
+	// for unchanged resources, so a stricter policy doesn't fail their plans.
+	if req.State.Raw.IsNull() || !resp.Plan.Raw.Equal(req.State.Raw) {
+		resp.Diagnostics.Append(r.checkExecPolicy(ctx, data)...)
+	}
+}
+
+// checkExecPolicy checks command or argv, on_destroy and check_command against
+// the provider's exec policy. Commands that are not known yet are checked
+// again before they run, and check_command is also checked on every refresh.
+func (r *LocalExecResource) checkExecPolicy(ctx context.Context, data LocalExecResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !allKnown(data.Interpreter, data.WorkingDir) {
+		return diags
+	}
+	interpreter, d := listValueToStrings(ctx, data.Interpreter)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	commands := []struct {
+		attribute string
+		value     types.String
+	}{
+		{"command", data.Command},
+		{"on_destroy", data.OnDestroy},
+		{"check_command", data.CheckCommand},
+	}
+	for _, c := range commands {
+		if c.value.IsNull() || c.value.IsUnknown() {
+			continue
+		}
+		command := localCommand{Command: c.value.ValueString(), Interpreter: interpreter, WorkingDir: r.config.resolvePath(data.WorkingDir.ValueString())}
+		r.config.applyDefaults(&command)
+		if err := r.config.checkCommand(command); err != nil {
+			diags.AddAttributeError(path.Root(c.attribute), "Command not allowed", err.Error())
+		}
+	}
+
+	if !data.Argv.IsNull() && allKnown(data.Argv) {
+		argv, d := listValueToStrings(ctx, data.Argv)
+		diags.Append(d...)
+		if len(argv) > 0 {
+			command := localCommand{Argv: argv, WorkingDir: r.config.resolvePath(data.WorkingDir.ValueString())}
+			if err := r.config.checkCommand(command); err != nil {
+				diags.AddAttributeError(path.Root("argv"), "Command not allowed", err.Error())
+			}
+		}
+	}
+
+	return diags
+}
+
 func (r *LocalExecResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// No configuration needed
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*localProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *localProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+	r.config = config
 }
 
 func (r *LocalExecResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -65,21 +289,61 @@ func (r *LocalExecResource) Create(ctx context.Context, req resource.CreateReque
 	if data.Output.IsNull() {
 		data.Output = types.StringValue("")
 	}
+	if data.Stdout.IsNull() {
+		data.Stdout = types.StringValue("")
+	}
+	if data.Stderr.IsNull() {
+		data.Stderr = types.StringValue("")
+	}
 	if data.ExitCode.IsNull() {
 		data.ExitCode = types.Int64Value(0)
 	}
 
-	// Generate a unique, stable ID before executing the command
-	data.Id = types.StringValue(generateExecID(data.Command.ValueString(), time.Now()))
+	command, diags := data.localCommand(ctx, r.config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	createTimeout, diags := data.Timeouts.Create(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := contextWithOptionalTimeout(ctx, createTimeout)
+	defer cancel()
+
+	data.Id, diags = localExecID(ctx, r.config, data.Command, data.Argv, data.Interpreter, data.Environment)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Commands that were unknown at plan time have not been checked yet
+	if err := r.config.checkCommand(command); err != nil {
+		resp.Diagnostics.AddError("Command not allowed", err.Error())
+		return
+	}
 
 	// Execute the command
-	output, exitCode, err := executeLocalCommand(data.Command.ValueString(), data.FailIfNonzero.ValueBool())
+	result, err := executeLocalCommand(ctx, command)
 	if err != nil {
 		resp.Diagnostics.AddError("Command execution failed", err.Error())
 		return
 	}
-	data.Output = types.StringValue(output)
-	data.ExitCode = types.Int64Value(exitCode)
+	data.Output = types.StringValue(result.Output)
+	data.Stdout = types.StringValue(result.Stdout)
+	data.Stderr = types.StringValue(result.Stderr)
+	data.ExitCode = types.Int64Value(result.ExitCode)
+	data.Attempts = types.Int64Value(result.Attempts)
+	data.Drifted = types.BoolValue(false)
+
+	// Parse the output according to output_format
+	data.Result, diags = commandResultValue(ctx, data.OutputFormat, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -92,7 +356,46 @@ func (r *LocalExecResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// No need to re-run the command during read
+	// The command itself is never re-run during read, but check_command can
+	// verify that its side effects are still in place
+	if !data.CheckCommand.IsNull() {
+		command, diags := data.localCommand(ctx, r.config)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		command.Command = data.CheckCommand.ValueString()
+		command.Argv = nil
+		command.Stdin = ""
+		command.Retry = retryPolicy{MaxAttempts: 1}
+		command.FailIfNonzero = false
+		if err := r.config.checkCommand(command); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("check_command"), "Command not allowed", err.Error())
+			return
+		}
+
+		readTimeout, diags := data.Timeouts.Read(ctx, 0)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ctx, cancel := contextWithOptionalTimeout(ctx, readTimeout)
+		defer cancel()
+
+		result, err := executeLocalCommand(ctx, command)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to execute check command", err.Error())
+			return
+		}
+
+		drifted := result.ExitCode != 0
+		if drifted && data.OnDrift.ValueString() != onDriftReport {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		data.Drifted = types.BoolValue(drifted)
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -112,17 +415,52 @@ func (r *LocalExecResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	// Preserve the original ID from state
-	data.Id = state.Id
+	id, diags := localExecID(ctx, r.config, data.Command, data.Argv, data.Interpreter, data.Environment)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Id = id
+
+	command, diags := data.localCommand(ctx, r.config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	updateTimeout, diags := data.Timeouts.Update(ctx, 0)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := contextWithOptionalTimeout(ctx, updateTimeout)
+	defer cancel()
+
+	// Commands that were unknown at plan time have not been checked yet
+	if err := r.config.checkCommand(command); err != nil {
+		resp.Diagnostics.AddError("Command not allowed", err.Error())
+		return
+	}
 
 	// Execute the command
-	output, exitCode, err := executeLocalCommand(data.Command.ValueString(), data.FailIfNonzero.ValueBool())
+	result, err := executeLocalCommand(ctx, command)
 	if err != nil {
 		resp.Diagnostics.AddError("Command execution failed", err.Error())
 		return
 	}
-	data.Output = types.StringValue(output)
-	data.ExitCode = types.Int64Value(exitCode)
+	data.Output = types.StringValue(result.Output)
+	data.Stdout = types.StringValue(result.Stdout)
+	data.Stderr = types.StringValue(result.Stderr)
+	data.ExitCode = types.Int64Value(result.ExitCode)
+	data.Attempts = types.Int64Value(result.Attempts)
+	data.Drifted = types.BoolValue(false)
+
+	// Parse the output according to output_format
+	data.Result, diags = commandResultValue(ctx, data.OutputFormat, result)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -135,9 +473,30 @@ func (r *LocalExecResource) Delete(ctx context.Context, req resource.DeleteReque
 		return
 	}
 
-	// If there's an on_destroy command, execute it
+	// If there's an on_destroy command, execute it with the same interpreter and environment
 	if !data.OnDestroy.IsNull() {
-		_, _, err := executeLocalCommand(data.OnDestroy.ValueString(), data.FailIfNonzero.ValueBool())
+		command, diags := data.localCommand(ctx, r.config)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		command.Command = data.OnDestroy.ValueString()
+		command.Argv = nil
+		command.Stdin = ""
+		if err := r.config.checkCommand(command); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("on_destroy"), "Command not allowed", err.Error())
+			return
+		}
+
+		deleteTimeout, diags := data.Timeouts.Delete(ctx, 0)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		ctx, cancel := contextWithOptionalTimeout(ctx, deleteTimeout)
+		defer cancel()
+
+		_, err := executeLocalCommand(ctx, command)
 		if err != nil {
 			resp.Diagnostics.AddError("Failed to execute destroy command", err.Error())
 			return
@@ -145,27 +504,546 @@ func (r *LocalExecResource) Delete(ctx context.Context, req resource.DeleteReque
 	}
 }
 
-func executeLocalCommand(command string, failIfNonzero bool) (string, int64, error) {
-	if command == "" {
-		return "", 0, fmt.Errorf("empty command")
+// ImportState adopts a command that has already been run outside of
+// Terraform. The import ID is the command, which is recorded without running
+// it, so the outputs stay empty until the resource is next updated.
+func (r *LocalExecResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID == "" {
+		resp.Diagnostics.AddError("Invalid import ID", "The import ID must be the command to record.")
+		return
+	}
+
+	id, diags := localExecID(ctx, r.config, types.StringValue(req.ID), types.ListNull(types.StringType), types.ListNull(types.StringType), types.MapNull(types.StringType))
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("command"), req.ID)...)
+
+	// Attributes with defaults are not set by the configuration on import
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("inherit_environment"), true)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("grace_period"), defaultGracePeriod.String())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("output_format"), outputFormatText)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("fail_if_nonzero"), r.config.failIfNonzero())...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("on_drift"), onDriftRecreate)...)
+}
+
+// UpgradeState migrates state from version 0, which used IDs derived from a
+// timestamp, to the deterministic IDs used since version 1.
+func (r *LocalExecResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := LocalExecResourceSchema
+	schemaV0.Version = 0
+
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schemaV0,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var data LocalExecResourceModel
+				resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				id, diags := localExecID(ctx, r.config, data.Command, data.Argv, data.Interpreter, data.Environment)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				data.Id = id
+
+				// Attributes added since version 0 are null in its state, which
+				// would plan an update, and so a re-run, to their defaults
+				data.InheritEnvironment = types.BoolValue(true)
+				data.GracePeriod = types.StringValue(defaultGracePeriod.String())
+				data.OutputFormat = types.StringValue(outputFormatText)
+				data.OnDrift = types.StringValue(onDriftRecreate)
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			},
+		},
+	}
+}
+
+func (m *LocalExecResourceModel) localCommand(ctx context.Context, config *localProviderConfig) (localCommand, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	interpreter, d := listValueToStrings(ctx, m.Interpreter)
+	diags.Append(d...)
+	argv, d := listValueToStrings(ctx, m.Argv)
+	diags.Append(d...)
+	environment, d := mergedEnvironment(ctx, m.Environment, m.SensitiveEnvironment)
+	diags.Append(d...)
+	passthrough, d := listValueToStrings(ctx, m.EnvironmentPassthrough)
+	diags.Append(d...)
+	timeout, d := parseDuration(m.Timeout, path.Root("timeout"))
+	diags.Append(d...)
+	gracePeriod, d := parseDuration(m.GracePeriod, path.Root("grace_period"))
+	diags.Append(d...)
+	retry, d := m.Retry.retryPolicy(ctx)
+	diags.Append(d...)
+
+	stdin := m.Stdin.ValueString()
+	if !m.SensitiveStdin.IsNull() {
+		stdin = m.SensitiveStdin.ValueString()
+	}
+
+	command := localCommand{
+		Command:                m.Command.ValueString(),
+		Interpreter:            interpreter,
+		Argv:                   argv,
+		Environment:            environment,
+		InheritEnvironment:     m.InheritEnvironment.IsNull() || m.InheritEnvironment.ValueBool(),
+		EnvironmentPassthrough: passthrough,
+		WorkingDir:             config.resolvePath(m.WorkingDir.ValueString()),
+		Stdin:                  stdin,
+		Timeout:                timeout,
+		GracePeriod:            gracePeriod,
+		Retry:                  retry,
+		FailIfNonzero:          m.FailIfNonzero.ValueBool(),
+	}
+	config.applyDefaults(&command)
+
+	return command, diags
+}
+
+// Supported values of the on_drift attribute.
+const (
+	onDriftRecreate = "recreate"
+	onDriftReport   = "report"
+)
+
+// defaultGracePeriod is how long a command is given to exit after SIGTERM
+// before it is killed, when no grace period is configured.
+const defaultGracePeriod = 10 * time.Second
+
+// LocalExecRetryModel describes the retry block of tf_local_exec.
+type LocalExecRetryModel struct {
+	MaxAttempts      types.Int64   `tfsdk:"max_attempts"`
+	InitialDelay     types.String  `tfsdk:"initial_delay"`
+	MaxDelay         types.String  `tfsdk:"max_delay"`
+	Multiplier       types.Float64 `tfsdk:"multiplier"`
+	RetryOnExitCodes types.List    `tfsdk:"retry_on_exit_codes"`
+}
+
+// retryPolicy returns the retry policy described by the block. A nil block
+// runs the command exactly once.
+func (m *LocalExecRetryModel) retryPolicy(ctx context.Context) (retryPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	policy := retryPolicy{MaxAttempts: 1}
+	if m == nil {
+		return policy, diags
+	}
+
+	policy = retryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+	}
+	retryPath := path.Root("retry")
+
+	if !m.MaxAttempts.IsNull() && !m.MaxAttempts.IsUnknown() {
+		policy.MaxAttempts = m.MaxAttempts.ValueInt64()
+		if policy.MaxAttempts < 1 {
+			diags.AddAttributeError(retryPath.AtName("max_attempts"), "Invalid retry policy", "max_attempts must be at least 1.")
+		}
+	}
+	if !m.InitialDelay.IsNull() {
+		delay, d := parseDuration(m.InitialDelay, retryPath.AtName("initial_delay"))
+		diags.Append(d...)
+		policy.InitialDelay = delay
+	}
+	if !m.MaxDelay.IsNull() {
+		delay, d := parseDuration(m.MaxDelay, retryPath.AtName("max_delay"))
+		diags.Append(d...)
+		policy.MaxDelay = delay
+	}
+	if !m.Multiplier.IsNull() && !m.Multiplier.IsUnknown() {
+		policy.Multiplier = m.Multiplier.ValueFloat64()
+		if policy.Multiplier < 1 {
+			diags.AddAttributeError(retryPath.AtName("multiplier"), "Invalid retry policy", "multiplier must be at least 1.")
+		}
+	}
+	if !m.RetryOnExitCodes.IsNull() && !m.RetryOnExitCodes.IsUnknown() {
+		diags.Append(m.RetryOnExitCodes.ElementsAs(ctx, &policy.RetryOnExitCodes, false)...)
+	}
+
+	return policy, diags
+}
+
+// retryPolicy controls how often and how fast a failing command is retried.
+type retryPolicy struct {
+	MaxAttempts      int64
+	InitialDelay     time.Duration
+	MaxDelay         time.Duration
+	Multiplier       float64
+	RetryOnExitCodes []int64
+}
+
+// retryable reports whether a command that exited with exitCode should be retried.
+func (p retryPolicy) retryable(exitCode int64) bool {
+	if exitCode == 0 {
+		return false
+	}
+	if len(p.RetryOnExitCodes) == 0 {
+		return true
+	}
+	for _, code := range p.RetryOnExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the delay before the given retry, starting at 1 for the first retry.
+func (p retryPolicy) delay(retry int64) time.Duration {
+	delay := float64(p.InitialDelay)
+	for i := int64(1); i < retry; i++ {
+		delay *= p.Multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// defaultInterpreter is used to run commands when no interpreter is configured.
+var defaultInterpreter = []string{"sh", "-c"}
+
+// localCommand describes a command to execute and how to execute it. Either
+// Command is run through Interpreter, or Argv is executed directly.
+type localCommand struct {
+	Command                string
+	Interpreter            []string
+	Argv                   []string
+	Environment            map[string]string
+	InheritEnvironment     bool
+	EnvironmentPassthrough []string
+	WorkingDir             string
+	Stdin                  string
+	Timeout                time.Duration
+	GracePeriod            time.Duration
+	Retry                  retryPolicy
+	FailIfNonzero          bool
+}
+
+// args returns the program and arguments to execute.
+func (c localCommand) args() []string {
+	if len(c.Argv) > 0 {
+		return c.Argv
+	}
+	interpreter := c.Interpreter
+	if len(interpreter) == 0 {
+		interpreter = defaultInterpreter
+	}
+	args := make([]string, 0, len(interpreter)+1)
+	args = append(args, interpreter...)
+	return append(args, c.Command)
+}
+
+// environ returns the environment of the command, starting from the provider
+// environment or the passthrough variables and adding Environment on top.
+func (c localCommand) environ() []string {
+	var env []string
+	if c.InheritEnvironment {
+		env = os.Environ()
+	} else {
+		for _, name := range c.EnvironmentPassthrough {
+			if value, ok := os.LookupEnv(name); ok {
+				env = append(env, name+"="+value)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(c.Environment))
+	for name := range c.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+c.Environment[name])
+	}
+
+	// An empty, non-nil slice keeps exec.Cmd from falling back to os.Environ
+	if env == nil {
+		env = []string{}
+	}
+	return env
+}
+
+// checkWorkingDir returns an error if dir is set but is not an existing directory.
+func checkWorkingDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("working directory %s does not exist", dir)
+		}
+		return fmt.Errorf("failed to access working directory %s: %v", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("working directory %s is not a directory", dir)
+	}
+	return nil
+}
+
+// mergedEnvironment combines environment and sensitive_environment, with
+// sensitive values taking precedence.
+func mergedEnvironment(ctx context.Context, environment types.Map, sensitiveEnvironment types.Map) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	result, d := mapValueToStrings(ctx, environment)
+	diags.Append(d...)
+	sensitive, d := mapValueToStrings(ctx, sensitiveEnvironment)
+	diags.Append(d...)
+
+	if result == nil {
+		result = map[string]string{}
+	}
+	for name, value := range sensitive {
+		result[name] = value
+	}
+	return result, diags
+}
+
+// gracePeriod returns the configured grace period, or the default if unset.
+func (c localCommand) gracePeriod() time.Duration {
+	if c.GracePeriod > 0 {
+		return c.GracePeriod
+	}
+	return defaultGracePeriod
+}
+
+// String returns a human-readable representation of the command.
+func (c localCommand) String() string {
+	if len(c.Argv) > 0 {
+		return strings.Join(c.Argv, " ")
+	}
+	return c.Command
+}
+
+// localExecID computes the ID of a command from its command, argv, interpreter
+// and environment attributes, with the provider defaults applied, so that the
+// ID reflects the command line that actually runs. The ID is unknown if any of
+// the attributes is unknown.
+func localExecID(ctx context.Context, config *localProviderConfig, command types.String, argv types.List, interpreter types.List, environment types.Map) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !allKnown(command, argv, interpreter, environment) {
+		return types.StringUnknown(), diags
+	}
+
+	argvStrings, d := listValueToStrings(ctx, argv)
+	diags.Append(d...)
+	interpreterStrings, d := listValueToStrings(ctx, interpreter)
+	diags.Append(d...)
+	environmentStrings, d := mapValueToStrings(ctx, environment)
+	diags.Append(d...)
+	if diags.HasError() {
+		return types.StringUnknown(), diags
+	}
+
+	c := localCommand{Command: command.ValueString(), Argv: argvStrings, Interpreter: interpreterStrings, Environment: environmentStrings}
+	config.applyDefaults(&c)
+	return types.StringValue(execID(c.args(), c.Environment)), diags
+}
+
+// validateLocalCommandConfig checks that exactly one of command and argv is
+// set, and that interpreter is only combined with command.
+func validateLocalCommandConfig(command types.String, interpreter types.List, argv types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if command.IsUnknown() || argv.IsUnknown() {
+		return diags
+	}
+
+	if command.IsNull() && argv.IsNull() {
+		diags.AddAttributeError(path.Root("command"), "Missing command", "Exactly one of command or argv must be set.")
+	}
+	if !command.IsNull() && !argv.IsNull() {
+		diags.AddAttributeError(path.Root("argv"), "Conflicting attributes", "Exactly one of command or argv must be set.")
+	}
+	if !argv.IsNull() && len(argv.Elements()) == 0 {
+		diags.AddAttributeError(path.Root("argv"), "Invalid argv", "argv must contain at least the program to execute.")
+	}
+	if !argv.IsNull() && !interpreter.IsNull() {
+		diags.AddAttributeError(path.Root("interpreter"), "Conflicting attributes", "interpreter cannot be combined with argv, which is executed without a shell.")
+	}
+	if !interpreter.IsNull() && !interpreter.IsUnknown() && len(interpreter.Elements()) == 0 {
+		diags.AddAttributeError(path.Root("interpreter"), "Invalid interpreter", "interpreter must contain at least the program to execute.")
+	}
+
+	return diags
+}
+
+// localCommandResult holds the captured output and exit code of a command.
+type localCommandResult struct {
+	Output   string
+	Stdout   string
+	Stderr   string
+	ExitCode int64
+	Attempts int64
+}
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent writes, used to
+// interleave stdout and stderr in the order they were written.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// executeLocalCommand runs the command, retrying non-zero exit codes according
+// to its retry policy, and fails on a final non-zero exit code if FailIfNonzero is set.
+func executeLocalCommand(ctx context.Context, command localCommand) (localCommandResult, error) {
+	var result localCommandResult
+	if len(command.Argv) == 0 && command.Command == "" {
+		return result, fmt.Errorf("empty command")
+	}
+
+	if err := checkWorkingDir(command.WorkingDir); err != nil {
+		return result, err
+	}
+
+	maxAttempts := command.Retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := int64(1); ; attempt++ {
+		var err error
+		result, err = runLocalCommand(ctx, command)
+		result.Attempts = attempt
+		if err != nil {
+			return result, err
+		}
+		if attempt >= maxAttempts || !command.Retry.retryable(result.ExitCode) {
+			break
+		}
+
+		// Wait before the next attempt, unless the operation is cancelled first
+		timer := time.NewTimer(command.Retry.delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("command was cancelled while waiting to retry after exit code %d: %s", result.ExitCode, result.Output)
+		}
+	}
+
+	if result.ExitCode != 0 && command.FailIfNonzero {
+		if result.Attempts > 1 {
+			return result, fmt.Errorf("command exited with code %d after %d attempts: %s", result.ExitCode, result.Attempts, result.Output)
+		}
+		return result, fmt.Errorf("command exited with code %d: %s", result.ExitCode, result.Output)
+	}
+
+	return result, nil
+}
+
+// runLocalCommand runs the command once and captures its output and exit code.
+// A non-zero exit code is not treated as an error.
+func runLocalCommand(ctx context.Context, command localCommand) (localCommandResult, error) {
+	var result localCommandResult
+
+	// Run the command through its interpreter, or directly when argv is given
+	args := command.args()
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = command.environ()
+	cmd.Dir = command.WorkingDir
+	if command.Stdin != "" {
+		cmd.Stdin = strings.NewReader(command.Stdin)
 	}
 
-	// Use the shell to execute the command
-	cmd := exec.Command("sh", "-c", command)
+	// Run the command in its own process group, so that it can be terminated
+	// together with any child processes it spawns
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	// Descendants that leave the process group, e.g. daemons, may keep stdout
+	// and stderr open after the command exits or is killed. Stop waiting for
+	// their output after the grace period rather than blocking indefinitely.
+	cmd.WaitDelay = command.gracePeriod()
+
+	// Capture stdout and stderr separately, while also keeping the combined stream
+	var stdout, stderr bytes.Buffer
+	var combined syncBuffer
+	cmd.Stdout = io.MultiWriter(&stdout, &combined)
+	cmd.Stderr = io.MultiWriter(&stderr, &combined)
+
+	ctx, cancel := contextWithOptionalTimeout(ctx, command.Timeout)
+	defer cancel()
 
-	// Execute the command and capture output
-	output, err := cmd.CombinedOutput()
-	exitCode := int64(0)
+	// Start the command and wait for it to exit, or for the context to be done
+	if err := cmd.Start(); err != nil {
+		return result, fmt.Errorf("failed to execute command: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		terminateProcessGroup(cmd.Process.Pid, done, command.gracePeriod())
+		result.Output = combined.String()
+		result.Stdout = stdout.String()
+		result.Stderr = stderr.String()
+		result.ExitCode = -1
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return result, fmt.Errorf("command timed out: %s", result.Output)
+		}
+		return result, fmt.Errorf("command was cancelled: %s", result.Output)
+	}
+
+	result.Output = combined.String()
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	// The command itself succeeded, only the output of its descendants was cut off
+	if errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = int64(exitErr.ExitCode())
-			if failIfNonzero {
-				return string(output), exitCode, fmt.Errorf("command exited with code %d: %s", exitCode, string(output))
-			}
+			result.ExitCode = int64(exitErr.ExitCode())
 		} else {
-			return string(output), 0, fmt.Errorf("failed to execute command: %v", err)
+			return result, fmt.Errorf("failed to execute command: %v", err)
 		}
 	}
 
-	return string(output), exitCode, nil
+	return result, nil
+}
+
+// terminateProcessGroup sends SIGTERM to the process group led by pid, and
+// SIGKILL if it has not exited within the grace period. done receives the
+// result of waiting for the process.
+func terminateProcessGroup(pid int, done <-chan error, gracePeriod time.Duration) {
+	_ = syscall.Kill(-pid, syscall.SIGTERM)
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		_ = syscall.Kill(-pid, syscall.SIGKILL)
+		<-done
+	}
 }
@@ -1,9 +1,25 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccLocalExecResource(t *testing.T) {
@@ -18,6 +34,7 @@ func TestAccLocalExecResource(t *testing.T) {
 					resource.TestCheckResourceAttr("tf_local_exec.basic", "command", "echo 'hello world'"),
 					resource.TestCheckResourceAttr("tf_local_exec.basic", "exit_code", "0"),
 					resource.TestCheckResourceAttr("tf_local_exec.basic", "output", "hello world\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.basic", "id", execID([]string{"sh", "-c", "echo 'hello world'"}, nil)),
 
 					// Non-zero exit with fail_if_nonzero = false
 					resource.TestCheckResourceAttr("tf_local_exec.nonzero_allowed", "command", "false"),
@@ -32,6 +49,25 @@ func TestAccLocalExecResource(t *testing.T) {
 					resource.TestCheckResourceAttr("tf_local_exec.multiline", "command", "echo \"Line 1\"\necho \"Line 2\"\n"),
 					resource.TestCheckResourceAttr("tf_local_exec.multiline", "exit_code", "0"),
 					resource.TestCheckResourceAttr("tf_local_exec.multiline", "output", "Line 1\nLine 2\n"),
+
+					// Separate stdout and stderr
+					resource.TestCheckResourceAttr("tf_local_exec.streams", "stdout", "result\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.streams", "stderr", "progress\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.streams", "output", "progress\nresult\n"),
+
+					// Custom interpreter
+					resource.TestCheckResourceAttr("tf_local_exec.bash", "output", "b\n"),
+
+					// Direct argv execution without a shell
+					resource.TestCheckResourceAttr("tf_local_exec.argv", "output", "a b; c\n"),
+
+					// Environment variables
+					resource.TestCheckResourceAttr("tf_local_exec.environment", "output", "tf token\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.environment", "inherit_environment", "true"),
+
+					// Standard input
+					resource.TestCheckResourceAttr("tf_local_exec.stdin", "output", "{\"key\":\"value\"}"),
+					resource.TestCheckResourceAttr("tf_local_exec.stdin", "result.key", "value"),
 				),
 			},
 			// Test updates to commands
@@ -73,6 +109,31 @@ resource "tf_local_exec" "multiline" {
     echo "Line 2"
   EOF
 }
+
+resource "tf_local_exec" "streams" {
+  command = "echo progress >&2; sleep 0.1; echo result"
+}
+
+resource "tf_local_exec" "bash" {
+  interpreter = ["bash", "-euo", "pipefail", "-c"]
+  command     = "arr=(a b c); echo \"$${arr[1]}\""
+}
+
+resource "tf_local_exec" "argv" {
+  argv = ["echo", "a b; c"]
+}
+
+resource "tf_local_exec" "environment" {
+  command               = "echo \"$APP $TOKEN\""
+  environment           = { APP = "tf" }
+  sensitive_environment = { TOKEN = "token" }
+}
+
+resource "tf_local_exec" "stdin" {
+  argv            = ["cat"]
+  sensitive_stdin = jsonencode({ key = "value" })
+  output_format   = "json"
+}
 `
 }
 
@@ -99,3 +160,637 @@ resource "tf_local_exec" "multiline" {
 }
 `
 }
+
+func TestAccLocalExecResource_WorkingDir(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.Mkdir(filepath.Join(tempDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Resolve symlinks, e.g. /tmp on macOS, so that pwd output matches
+	tempDir, err = filepath.EvalSymlinks(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "tf" {
+  base_dir = "%s"
+}
+
+resource "tf_local_exec" "absolute" {
+  command     = "pwd"
+  working_dir = "%s"
+}
+
+resource "tf_local_exec" "relative" {
+  command     = "pwd"
+  working_dir = "sub"
+}
+`, tempDir, tempDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.absolute", "output", tempDir+"\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.relative", "output", filepath.Join(tempDir, "sub")+"\n"),
+				),
+			},
+			{
+				Config: `
+resource "tf_local_exec" "missing" {
+  command     = "pwd"
+  working_dir = "/nonexistent/dir"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`working directory /nonexistent/dir does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccLocalExecResource_Timeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "tf_local_exec" "fast" {
+  command = "echo done"
+  timeout = "10s"
+
+  timeouts {
+    create = "1m"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.fast", "output", "done\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.fast", "grace_period", "10s"),
+				),
+			},
+			{
+				Config: `
+resource "tf_local_exec" "slow" {
+  command = "trap '' TERM; sleep 30"
+
+  timeouts {
+    create = "1s"
+  }
+  grace_period = "1s"
+}
+`,
+				ExpectError: regexp.MustCompile(`command timed out`),
+			},
+			{
+				Config: `
+resource "tf_local_exec" "invalid" {
+  command = "echo hi"
+  timeout = "soon"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Invalid duration`),
+			},
+		},
+	})
+}
+
+func TestRunLocalCommand_DetachedDescendant(t *testing.T) {
+	if _, err := exec.LookPath("setsid"); err != nil {
+		t.Skip("setsid not found")
+	}
+
+	tests := []struct {
+		name    string
+		command localCommand
+		error   string
+	}{
+		// The backgrounded child leaves the process group, so it survives the
+		// SIGKILL and keeps stdout open
+		{
+			name:    "timeout",
+			command: localCommand{Command: "setsid sleep 10 & sleep 30", Timeout: 500 * time.Millisecond, GracePeriod: 500 * time.Millisecond},
+			error:   "command timed out",
+		},
+		{
+			name:    "exit",
+			command: localCommand{Command: "setsid sleep 10 & echo started", GracePeriod: 500 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			result, err := runLocalCommand(context.Background(), tt.command)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("expected the command to return within the timeout and grace periods, took %s", elapsed)
+			}
+			if tt.error == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.ExitCode != 0 || result.Stdout != "started\n" {
+					t.Errorf("expected exit code 0 and output %q, got %d and %q", "started\n", result.ExitCode, result.Stdout)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+}
+
+func TestAccLocalExecResource_Retry(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The command fails until it has been run three times
+	counter := filepath.Join(tempDir, "counter")
+	command := fmt.Sprintf(`n=$(cat %[1]s 2>/dev/null || echo 0); n=$((n+1)); echo $n > %[1]s; echo attempt $n; [ $n -ge 3 ]`, counter)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "tf_local_exec" "flaky" {
+  command = %q
+
+  retry {
+    max_attempts  = 5
+    initial_delay = "100ms"
+    max_delay     = "200ms"
+  }
+}
+
+resource "tf_local_exec" "not_retried" {
+  command         = "exit 2"
+  fail_if_nonzero = false
+
+  retry {
+    initial_delay       = "100ms"
+    retry_on_exit_codes = [75]
+  }
+}
+
+resource "tf_local_exec" "no_retry" {
+  command = "echo once"
+}
+`, command),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.flaky", "exit_code", "0"),
+					resource.TestCheckResourceAttr("tf_local_exec.flaky", "attempts", "3"),
+					resource.TestCheckResourceAttr("tf_local_exec.flaky", "output", "attempt 3\n"),
+
+					resource.TestCheckResourceAttr("tf_local_exec.not_retried", "exit_code", "2"),
+					resource.TestCheckResourceAttr("tf_local_exec.not_retried", "attempts", "1"),
+
+					resource.TestCheckResourceAttr("tf_local_exec.no_retry", "attempts", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalExecResource_Triggers(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	runs := filepath.Join(tempDir, "runs")
+	destroyed := filepath.Join(tempDir, "destroyed")
+
+	config := func(trigger string, replace string) string {
+		return fmt.Sprintf(`
+resource "tf_local_exec" "triggered" {
+  command    = "echo run >> %s; wc -l < %s | tr -d ' '"
+  on_destroy = "echo destroyed >> %s"
+
+  triggers = {
+    version = %q
+  }
+  triggers_replace = {
+    generation = %q
+  }
+}
+`, runs, runs, destroyed, trigger, replace)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("1", "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.triggered", "output", "1\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.triggered", "triggers.version", "1"),
+				),
+			},
+			// Changing triggers re-runs the command in place
+			{
+				Config: config("2", "1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_exec.triggered", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.triggered", "output", "2\n"),
+					testCheckFileContent(destroyed, ""),
+				),
+			},
+			// Changing triggers_replace destroys and re-creates the resource
+			{
+				Config: config("2", "2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_exec.triggered", plancheck.ResourceActionDestroyBeforeCreate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.triggered", "output", "3\n"),
+					testCheckFileContent(destroyed, "destroyed\n"),
+				),
+			},
+			// Unchanged configuration does not re-run the command
+			{
+				Config: config("2", "2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+// testCheckFileContent checks the content of a file on disk, treating a missing file as empty.
+func testCheckFileContent(path string, expected string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if string(content) != expected {
+			return fmt.Errorf("expected %s to contain %q, got %q", path, expected, string(content))
+		}
+		return nil
+	}
+}
+
+func TestAccLocalExecResource_CheckCommand(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	recreated := filepath.Join(tempDir, "recreated")
+	reported := filepath.Join(tempDir, "reported")
+
+	config := fmt.Sprintf(`
+resource "tf_local_exec" "recreate" {
+  command       = "touch %[1]s"
+  check_command = "test -f %[1]s"
+}
+
+resource "tf_local_exec" "report" {
+  command       = "touch %[2]s"
+  check_command = "test -f %[2]s"
+  on_drift      = "report"
+}
+`, recreated, reported)
+
+	removeFiles := func() {
+		for _, file := range []string{recreated, reported} {
+			if err := os.Remove(file); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.recreate", "drifted", "false"),
+					resource.TestCheckResourceAttr("tf_local_exec.recreate", "on_drift", "recreate"),
+					resource.TestCheckResourceAttr("tf_local_exec.report", "drifted", "false"),
+				),
+			},
+			// Removing the side effects re-creates one resource and reports drift on the other
+			{
+				PreConfig: removeFiles,
+				Config:    config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_exec.recreate", plancheck.ResourceActionCreate),
+						plancheck.ExpectResourceAction("tf_local_exec.report", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileExists(recreated),
+					resource.TestCheckResourceAttr("tf_local_exec.recreate", "drifted", "false"),
+					resource.TestCheckResourceAttr("tf_local_exec.report", "drifted", "true"),
+				),
+			},
+		},
+	})
+}
+
+// testCheckFileExists checks that a file exists on disk.
+func testCheckFileExists(path string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		_, err := os.Stat(path)
+		return err
+	}
+}
+
+func TestAccLocalExecResource_Import(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	marker := filepath.Join(tempDir, "marker")
+	command := fmt.Sprintf("touch %s", marker)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The command is recorded without running it
+			{
+				Config: fmt.Sprintf(`
+import {
+  to = tf_local_exec.setup
+  id = "%[1]s"
+}
+
+resource "tf_local_exec" "setup" {
+  command = "%[1]s"
+}
+`, command),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_exec.setup", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.setup", "command", command),
+					resource.TestCheckNoResourceAttr("tf_local_exec.setup", "output"),
+					func(_ *terraform.State) error {
+						if _, err := os.Stat(marker); !os.IsNotExist(err) {
+							return fmt.Errorf("expected command not to run on import, got %v", err)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalExecResource_ID(t *testing.T) {
+	config := func(region string) string {
+		return fmt.Sprintf(`
+resource "tf_local_exec" "deploy" {
+  command = "echo $REGION"
+  environment = {
+    REGION = "%s"
+  }
+  sensitive_environment = {
+    TOKEN = "secret"
+  }
+}
+`, region)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// The ID is derived from the command line and environment, and known at plan time
+			{
+				Config: config("eu-west-1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("tf_local_exec.deploy", tfjsonpath.New("id"), knownvalue.StringExact(execID([]string{"sh", "-c", "echo $REGION"}, map[string]string{"REGION": "eu-west-1"}))),
+					},
+				},
+			},
+			{
+				Config: config("us-east-1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_exec.deploy", plancheck.ResourceActionUpdate),
+						plancheck.ExpectKnownValue("tf_local_exec.deploy", tfjsonpath.New("id"), knownvalue.StringExact(execID([]string{"sh", "-c", "echo $REGION"}, map[string]string{"REGION": "us-east-1"}))),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestLocalExecID(t *testing.T) {
+	ctx := context.Background()
+
+	config := &localProviderConfig{
+		DefaultInterpreter: []string{"bash", "-c"},
+		DefaultEnvironment: map[string]string{"GREETING": "hello", "REGION": "default"},
+	}
+	command := types.StringValue("echo $GREETING $REGION")
+	environment := types.MapValueMust(types.StringType, map[string]attr.Value{"REGION": types.StringValue("eu-west-1")})
+	noList := types.ListNull(types.StringType)
+
+	tests := []struct {
+		name        string
+		config      *localProviderConfig
+		interpreter types.List
+		expected    string
+	}{
+		{
+			name:        "without provider defaults",
+			interpreter: noList,
+			expected:    execID([]string{"sh", "-c", "echo $GREETING $REGION"}, map[string]string{"REGION": "eu-west-1"}),
+		},
+		{
+			name:        "with provider defaults",
+			config:      config,
+			interpreter: noList,
+			expected:    execID([]string{"bash", "-c", "echo $GREETING $REGION"}, map[string]string{"GREETING": "hello", "REGION": "eu-west-1"}),
+		},
+		{
+			name:        "interpreter overrides default_interpreter",
+			config:      config,
+			interpreter: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("zsh"), types.StringValue("-c")}),
+			expected:    execID([]string{"zsh", "-c", "echo $GREETING $REGION"}, map[string]string{"GREETING": "hello", "REGION": "eu-west-1"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, diags := localExecID(ctx, tt.config, command, noList, tt.interpreter, environment)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if id.ValueString() != tt.expected {
+				t.Errorf("expected id %q, got %q", tt.expected, id.ValueString())
+			}
+		})
+	}
+}
+
+func TestAccLocalExecResource_ExecPolicy(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	marker := filepath.Join(tempDir, "marker")
+	providerConfig := `
+provider "tf" {
+  exec_policy {
+    allowed_executables = ["sh"]
+    denied_commands     = ["\\brm\\s+-rf\\b"]
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Violations fail at plan time and name the rule
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_exec" "test" {
+  command = "touch %s && rm -rf %s"
+}
+`, marker, marker),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`denied_commands\s+rule`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_exec" "test" {
+  argv = ["touch", "%s"]
+}
+`, marker),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`allowed_executables\s+rule`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_exec" "test" {
+  command    = "touch %s"
+  on_destroy = "rm -rf %s"
+}
+`, marker, marker),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`denied_commands\s+rule`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+data "tf_local_exec" "test" {
+  argv = ["touch", "%s"]
+}
+`, marker),
+				ExpectError: regexp.MustCompile(`allowed_executables\s+rule`),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "tf" {
+  exec_policy {
+    disable_exec = true
+  }
+}
+
+resource "tf_local_exec" "test" {
+  command = "touch %s"
+}
+`, marker),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`disable_exec`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_exec" "test" {
+  command = "test ! -e %[1]s && touch %[1]s"
+}
+`, marker),
+				Check: testCheckFileExists(marker),
+			},
+			// Existing resources that would not run again still plan cleanly
+			{
+				Config: fmt.Sprintf(`
+provider "tf" {
+  exec_policy {
+    disable_exec = true
+  }
+}
+
+resource "tf_local_exec" "test" {
+  command = "test ! -e %[1]s && touch %[1]s"
+}
+`, marker),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestLocalExecResource_UpgradeState(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := testAccProtoV6ProviderFactories["tf"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// State written by version 0, with a timestamp-based ID
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "tf_local_exec",
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: []byte(`{"command": "echo hi", "output": "hi\n", "exit_code": 0, "fail_if_nonzero": true, "on_destroy": null, "id": "178e0475d40fb383cba4dbbb9312cd21"}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics[0])
+	}
+
+	typ := LocalExecResourceSchema.Type().TerraformType(ctx)
+	testCheckUpgradedID(t, resp.UpgradedState, typ, execID([]string{"sh", "-c", "echo hi"}, nil))
+
+	// The unchanged configuration must not plan an update, which would run
+	// the command again
+	testCheckUpgradedStatePlansNoChanges(t, server, "tf_local_exec", typ, resp.UpgradedState, map[string]tftypes.Value{
+		"command": tftypes.NewValue(tftypes.String, "echo hi"),
+	})
+}
@@ -2,30 +2,58 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
 	"os"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type LocalFileDataSourceModel struct {
-	Path         types.String `tfsdk:"path"`
-	Content      types.String `tfsdk:"content"`
-	Permissions  types.String `tfsdk:"permissions"`
-	FailIfAbsent types.Bool   `tfsdk:"fail_if_absent"`
-	Id           types.String `tfsdk:"id"`
+	Path                types.String `tfsdk:"path"`
+	Content             types.String `tfsdk:"content"`
+	ContentBase64       types.String `tfsdk:"content_base64"`
+	Permissions         types.String `tfsdk:"permissions"`
+	FailIfAbsent        types.Bool   `tfsdk:"fail_if_absent"`
+	Exists              types.Bool   `tfsdk:"exists"`
+	Size                types.Int64  `tfsdk:"size"`
+	Mtime               types.String `tfsdk:"mtime"`
+	Owner               types.String `tfsdk:"owner"`
+	Group               types.String `tfsdk:"group"`
+	IsSymlink           types.Bool   `tfsdk:"is_symlink"`
+	ContentMd5          types.String `tfsdk:"content_md5"`
+	ContentSha1         types.String `tfsdk:"content_sha1"`
+	ContentSha256       types.String `tfsdk:"content_sha256"`
+	ContentSha512       types.String `tfsdk:"content_sha512"`
+	ContentBase64Sha256 types.String `tfsdk:"content_base64sha256"`
+	Id                  types.String `tfsdk:"id"`
 }
 
 var LocalFileDataSourceSchema = schema.Schema{
 	Description: "Read local files",
 	Attributes: map[string]schema.Attribute{
-		"path":           schema.StringAttribute{Required: true, Description: "Path to the file"},
-		"content":        schema.StringAttribute{Computed: true, Description: "Content of the file"},
-		"permissions":    schema.StringAttribute{Computed: true, Optional: true, Description: "File permissions (e.g., '0644')"},
-		"fail_if_absent": schema.BoolAttribute{Optional: true, Description: "Whether to fail if the file does not exist"},
-		"id":             schema.StringAttribute{Computed: true, Description: "Unique identifier for this file"},
+		"path":                 schema.StringAttribute{Required: true, Description: "Path to the file"},
+		"content":              schema.StringAttribute{Computed: true, Description: "Content of the file. Null if the file does not exist."},
+		"content_base64":       schema.StringAttribute{Computed: true, Description: "Base64-encoded content of the file, preserving binary content verbatim. Null if the file does not exist."},
+		"permissions":          schema.StringAttribute{Optional: true, Computed: true, DeprecationMessage: "Setting permissions on the tf_local_file data source has no effect. Remove it from the configuration; it will become read-only in a future version.", Description: "File permissions in octal notation (e.g., '0644'). Null if the file does not exist. Setting it is deprecated, and a configured value is returned unchanged."},
+		"fail_if_absent":       schema.BoolAttribute{Optional: true, Description: "Whether to fail if the file does not exist. If unset, a missing file has null content and checksums and a warning is shown; set it to false to allow missing files without a warning, and branch on exists."},
+		"exists":               schema.BoolAttribute{Computed: true, Description: "Whether the file exists"},
+		"size":                 schema.Int64Attribute{Computed: true, Description: "Size of the file in bytes. Null if the file does not exist."},
+		"mtime":                schema.StringAttribute{Computed: true, Description: "Modification time of the file in RFC 3339 format. Null if the file does not exist."},
+		"owner":                schema.StringAttribute{Computed: true, Description: "User that owns the file, as a name if it has one or a numeric user ID. Null if the file does not exist."},
+		"group":                schema.StringAttribute{Computed: true, Description: "Group that owns the file, as a name if it has one or a numeric group ID. Null if the file does not exist."},
+		"is_symlink":           schema.BoolAttribute{Computed: true, Description: "Whether path is a symbolic link. The other attributes describe the file it points to."},
+		"content_md5":          schema.StringAttribute{Computed: true, Description: "MD5 checksum of the file content, hex-encoded. Null if the file does not exist."},
+		"content_sha1":         schema.StringAttribute{Computed: true, Description: "SHA1 checksum of the file content, hex-encoded. Null if the file does not exist."},
+		"content_sha256":       schema.StringAttribute{Computed: true, Description: "SHA256 checksum of the file content, hex-encoded. Null if the file does not exist."},
+		"content_sha512":       schema.StringAttribute{Computed: true, Description: "SHA512 checksum of the file content, hex-encoded. Null if the file does not exist."},
+		"content_base64sha256": schema.StringAttribute{Computed: true, Description: "SHA256 checksum of the file content, base64-encoded. Null if the file does not exist."},
+		"id":                   schema.StringAttribute{Computed: true, Description: "Absolute path of the file"},
 	},
 }
 
@@ -35,7 +63,9 @@ func NewLocalFileDataSource() datasource.DataSource {
 	return &LocalFileDataSource{}
 }
 
-type LocalFileDataSource struct{}
+type LocalFileDataSource struct {
+	config *localProviderConfig
+}
 
 func (d *LocalFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_local_file"
@@ -46,7 +76,16 @@ func (d *LocalFileDataSource) Schema(_ context.Context, _ datasource.SchemaReque
 }
 
 func (d *LocalFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
-	// No configuration needed
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*localProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type", fmt.Sprintf("Expected *localProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+	d.config = config
 }
 
 func (d *LocalFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -57,20 +96,70 @@ func (d *LocalFileDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 
-	// Generate a unique ID early, based on the path
-	data.Id = types.StringValue(generateFileID(data.Path.ValueString(), time.Now()))
+	if err := d.config.checkPath(data.Path.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+		return
+	}
 
-	content, err := os.ReadFile(data.Path.ValueString())
+	id, err := fileID(data.Path.ValueString())
 	if err != nil {
-		if data.FailIfAbsent.ValueBool() {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+		return
+	}
+	data.Id = types.StringValue(id)
+
+	// A dangling symlink is a symlink, but the file does not exist
+	linkInfo, err := os.Lstat(data.Path.ValueString())
+	data.IsSymlink = types.BoolValue(err == nil && linkInfo.Mode()&fs.ModeSymlink != 0)
+
+	content, err := os.ReadFile(data.Path.ValueString())
+	switch {
+	case err == nil:
+		data.Exists = types.BoolValue(true)
+	case !os.IsNotExist(err) || data.FailIfAbsent.ValueBool():
+		resp.Diagnostics.AddError("Failed to read file", err.Error())
+		return
+	default:
+		// Missing files have null content, with a warning unless explicitly allowed
+		data.Exists = types.BoolValue(false)
+		if data.FailIfAbsent.IsNull() {
+			resp.Diagnostics.AddAttributeWarning(path.Root("path"), "File does not exist", fmt.Sprintf("%s does not exist, so its content and checksums are null. Set fail_if_absent = true to fail instead, or fail_if_absent = false to allow missing files and check the exists attribute.", data.Path.ValueString()))
+		}
+	}
+
+	if data.Exists.ValueBool() {
+		info, err := os.Stat(data.Path.ValueString())
+		if err != nil {
 			resp.Diagnostics.AddError("Failed to read file", err.Error())
 			return
 		}
-		// If fail_if_absent is false, return empty content
-		data.Content = types.StringValue("")
-	} else {
+		ownership, err := fileOwnershipOf(info)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to read file ownership", err.Error())
+			return
+		}
+		// A configured value must be returned as is
+		if data.Permissions.IsNull() {
+			data.Permissions = types.StringValue(formatFileMode(fileModeBits(info.Mode())))
+		}
+		data.Size = types.Int64Value(info.Size())
+		data.Mtime = types.StringValue(info.ModTime().UTC().Format(time.RFC3339))
+		data.Owner = types.StringValue(formatOwner(ownership.UID))
+		data.Group = types.StringValue(formatGroup(ownership.GID))
+
 		data.Content = types.StringValue(string(content))
+		data.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+		data.setContentHashes(hashContent(content))
 	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
+
+// setContentHashes sets the content_* checksum attributes.
+func (m *LocalFileDataSourceModel) setContentHashes(hashes contentHashes) {
+	m.ContentMd5 = types.StringValue(hashes.MD5)
+	m.ContentSha1 = types.StringValue(hashes.SHA1)
+	m.ContentSha256 = types.StringValue(hashes.SHA256)
+	m.ContentSha512 = types.StringValue(hashes.SHA512)
+	m.ContentBase64Sha256 = types.StringValue(hashes.Base64SHA256)
+}
@@ -3,8 +3,10 @@ package provider
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
@@ -33,11 +35,34 @@ func TestAccLocalFileDataSource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					// Test reading an existing file
 					resource.TestCheckResourceAttr("data.tf_local_file.test", "path", tempFile.Name()),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "id", tempFile.Name()),
 					resource.TestCheckResourceAttr("data.tf_local_file.test", "content", content),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_base64", "SGVsbG8sIFdvcmxkIQ=="),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_md5", "65a8e27d8879283831b664bd8b7f0ad4"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_sha1", "0a0a9f2a6772942557ab5355d76af442f8f65e01"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_sha256", "dffd6021bb2bd5b0af676290809ec3a53191dd81c7f70a4b28688a362182986f"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_sha512", "374d794a95cdcfd8b35993185fef9ba368f160d8daf432d08ba9f1ed1e5abe6cc69291e0fa2fe0006a52570ef18c19def4e617c33ce52ef0a6e5fbe318cb0387"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "content_base64sha256", "3/1gIbsr1bCvZ2KQgJ7DpTGR3YHH9wpLKGiKNiGCmG8="),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "exists", "true"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "size", "13"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "permissions", "0600"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "owner", formatOwner(os.Getuid())),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "group", formatGroup(os.Getgid())),
+					resource.TestCheckResourceAttrSet("data.tf_local_file.test", "mtime"),
+					resource.TestCheckResourceAttr("data.tf_local_file.test", "is_symlink", "false"),
 
 					// Test reading a non-existent file with fail_if_absent = false
 					resource.TestCheckResourceAttr("data.tf_local_file.missing_optional", "path", "/nonexistent/file"),
-					resource.TestCheckResourceAttr("data.tf_local_file.missing_optional", "content", ""),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.missing_optional", "content"),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.missing_optional", "content_base64"),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.missing_optional", "content_sha256"),
+					resource.TestCheckResourceAttr("data.tf_local_file.missing_optional", "exists", "false"),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.missing_optional", "permissions"),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.missing_optional", "size"),
+
+					// Configured permissions are deprecated, but still accepted
+					resource.TestCheckResourceAttr("data.tf_local_file.configured_permissions", "permissions", "0644"),
+					resource.TestCheckResourceAttr("data.tf_local_file.configured_permissions", "content", content),
 				),
 			},
 		},
@@ -54,7 +79,12 @@ data "tf_local_file" "missing_optional" {
 	path = "/nonexistent/file"
 	fail_if_absent = false
 }
-`, filePath)
+
+data "tf_local_file" "configured_permissions" {
+	path        = "%s"
+	permissions = "0644"
+}
+`, filePath, filePath)
 }
 
 // Test for expected failure when reading non-existent file with fail_if_absent = true
@@ -79,3 +109,100 @@ data "tf_local_file" "missing_required" {
 }
 `
 }
+
+func TestAccLocalFileDataSource_Binary(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "image.bin")
+	if err := os.WriteFile(file, []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0xff, 0xfe, 0x0a}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "tf_local_file" "image" {
+  path = "%s"
+}
+
+# Binary content round-trips through content_base64
+resource "tf_local_file" "copy" {
+  path           = "%s.copy"
+  content_base64 = data.tf_local_file.image.content_base64
+}
+`, file, file),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.tf_local_file.image", "content_base64", "iVBORwD//go="),
+					testCheckFileBytes(file+".copy", []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0xff, 0xfe, 0x0a}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalFileDataSource_Symlink(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	target := filepath.Join(tempDir, "target.conf")
+	if err := os.WriteFile(target, []byte("value"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(target, 0640); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(target, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(tempDir, "current.conf")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	dangling := filepath.Join(tempDir, "dangling.conf")
+	if err := os.Symlink(filepath.Join(tempDir, "missing"), dangling); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "tf_local_file" "link" {
+  path = "%s"
+}
+
+data "tf_local_file" "dangling" {
+  path           = "%s"
+  fail_if_absent = false
+}
+`, link, dangling),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					// Symlinks are followed
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "is_symlink", "true"),
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "exists", "true"),
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "content", "value"),
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "permissions", "0640"),
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "size", "5"),
+					resource.TestCheckResourceAttr("data.tf_local_file.link", "mtime", "2024-05-01T12:30:00Z"),
+
+					resource.TestCheckResourceAttr("data.tf_local_file.dangling", "is_symlink", "true"),
+					resource.TestCheckResourceAttr("data.tf_local_file.dangling", "exists", "false"),
+					resource.TestCheckNoResourceAttr("data.tf_local_file.dangling", "content_md5"),
+				),
+			},
+		},
+	})
+}
@@ -1,11 +1,19 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"time"
+	"syscall"
+	"unicode/utf8"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -14,33 +22,73 @@ import (
 )
 
 type LocalFileResourceModel struct {
-	Path            types.String `tfsdk:"path"`
-	Content         types.String `tfsdk:"content"`
-	Permissions     types.String `tfsdk:"permissions"`
-	FailIfAbsent    types.Bool   `tfsdk:"fail_if_absent"`
-	DeleteOnDestroy types.Bool   `tfsdk:"delete_on_destroy"`
-	Id              types.String `tfsdk:"id"`
+	Path                 types.String `tfsdk:"path"`
+	Content              types.String `tfsdk:"content"`
+	ContentBase64        types.String `tfsdk:"content_base64"`
+	SensitiveContent     types.String `tfsdk:"sensitive_content"`
+	ContentWo            types.String `tfsdk:"content_wo"`
+	ContentWoVersion     types.Int64  `tfsdk:"content_wo_version"`
+	Permissions          types.String `tfsdk:"permissions"`
+	Owner                types.String `tfsdk:"owner"`
+	Group                types.String `tfsdk:"group"`
+	CreateDirectories    types.Bool   `tfsdk:"create_directories"`
+	DirectoryPermissions types.String `tfsdk:"directory_permissions"`
+	CreatedDirectories   types.List   `tfsdk:"created_directories"`
+	FailIfAbsent         types.Bool   `tfsdk:"fail_if_absent"`
+	DeleteOnDestroy      types.Bool   `tfsdk:"delete_on_destroy"`
+	Atomic               types.Bool   `tfsdk:"atomic"`
+	ContentMd5           types.String `tfsdk:"content_md5"`
+	ContentSha1          types.String `tfsdk:"content_sha1"`
+	ContentSha256        types.String `tfsdk:"content_sha256"`
+	ContentSha512        types.String `tfsdk:"content_sha512"`
+	ContentBase64Sha256  types.String `tfsdk:"content_base64sha256"`
+	ContentDigest        types.String `tfsdk:"content_digest"`
+	Id                   types.String `tfsdk:"id"`
 }
 
 var LocalFileResourceSchema = schema.Schema{
 	Description: "Manage local files with potential side effects",
+	// Version 1 replaced timestamp-based IDs with deterministic ones
+	Version: 1,
 	Attributes: map[string]schema.Attribute{
-		"path":              schema.StringAttribute{Required: true, Description: "Path to the file"},
-		"content":           schema.StringAttribute{Required: true, Description: "Content of the file"},
-		"permissions":       schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString("0644"), Description: "File permissions (e.g., '0644')"},
-		"fail_if_absent":    schema.BoolAttribute{Optional: true, Description: "Whether to fail if the file does not exist"},
-		"delete_on_destroy": schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether to delete the file when the resource is destroyed. Defaults to true."},
-		"id":                schema.StringAttribute{Computed: true, Description: "Unique identifier for this file"},
+		"path":                  schema.StringAttribute{Required: true, Description: "Path to the file"},
+		"content":               schema.StringAttribute{Optional: true, Description: "Content of the file. Exactly one of content, content_base64, sensitive_content or content_wo must be set."},
+		"content_base64":        schema.StringAttribute{Optional: true, Description: "Base64-encoded content of the file, for binary content. Exactly one of content, content_base64, sensitive_content or content_wo must be set."},
+		"sensitive_content":     schema.StringAttribute{Optional: true, Sensitive: true, Description: "Content of the file, hidden from plan output. It is still stored in state; use content_wo to keep it out of state. Exactly one of content, content_base64, sensitive_content or content_wo must be set."},
+		"content_wo":            schema.StringAttribute{Optional: true, Sensitive: true, WriteOnly: true, Description: "Write-only content of the file, which is written to disk but never stored in plan or state. Requires Terraform 1.11 or later. Exactly one of content, content_base64, sensitive_content or content_wo must be set."},
+		"content_wo_version":    schema.Int64Attribute{Optional: true, Description: "Version of content_wo. Changing it forces the file to be rewritten with the current content_wo."},
+		"permissions":           schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString("0644"), Description: "File permissions in octal (e.g., '0644') or symbolic notation (e.g., 'u=rw,go=r'). Symbolic permissions are applied to 0666, so 'go-w' results in 0644. Defaults to '0644'."},
+		"owner":                 schema.StringAttribute{Optional: true, Computed: true, Description: "User that owns the file, as a name or numeric user ID. Changing the owner requires running Terraform as root or with the CAP_CHOWN capability. Defaults to the user running Terraform, and is only enforced when set."},
+		"group":                 schema.StringAttribute{Optional: true, Computed: true, Description: "Group that owns the file, as a name or numeric group ID. Defaults to the primary group of the user running Terraform, and is only enforced when set."},
+		"create_directories":    schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether to create missing parent directories. Defaults to true."},
+		"directory_permissions": schema.StringAttribute{Optional: true, Computed: true, Default: stringdefault.StaticString("0755"), Description: "Permissions of parent directories created for the file, in octal or symbolic notation, where symbolic permissions are applied to 0777. Created directories also get the file's owner and group. Existing directories are left unchanged. Defaults to '0755'."},
+		"created_directories":   schema.ListAttribute{ElementType: types.StringType, Computed: true, Description: "Parent directories created for the file, outermost first. They are removed on destroy if they are empty."},
+		"fail_if_absent":        schema.BoolAttribute{Optional: true, Description: "Whether to fail if the file does not exist"},
+		"delete_on_destroy":     schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether to delete the file when the resource is destroyed. Defaults to true."},
+		"atomic":                schema.BoolAttribute{Optional: true, Computed: true, Default: booldefault.StaticBool(true), Description: "Whether to write the file atomically, by writing to a temporary file in the same directory and renaming it over the target, so that readers never see a partially written file. Defaults to true."},
+		"content_md5":           schema.StringAttribute{Computed: true, Description: "MD5 checksum of the file content, hex-encoded. Null for sensitive_content and content_wo, see content_digest."},
+		"content_sha1":          schema.StringAttribute{Computed: true, Description: "SHA1 checksum of the file content, hex-encoded. Null for sensitive_content and content_wo, see content_digest."},
+		"content_sha256":        schema.StringAttribute{Computed: true, Description: "SHA256 checksum of the file content, hex-encoded. Null for sensitive_content and content_wo, see content_digest."},
+		"content_sha512":        schema.StringAttribute{Computed: true, Description: "SHA512 checksum of the file content, hex-encoded. Null for sensitive_content and content_wo, see content_digest."},
+		"content_base64sha256":  schema.StringAttribute{Computed: true, Description: "SHA256 checksum of the file content, base64-encoded. Null for sensitive_content and content_wo, see content_digest."},
+		"content_digest":        schema.StringAttribute{Computed: true, Sensitive: true, Description: "Salted HMAC-SHA256 of sensitive_content or content_wo, used to detect changes and drift without exposing checksums of secret content. Null for content and content_base64."},
+		"id":                    schema.StringAttribute{Computed: true, Description: "Absolute path of the file"},
 	},
 }
 
 var _ resource.Resource = &LocalFileResource{}
+var _ resource.ResourceWithValidateConfig = &LocalFileResource{}
+var _ resource.ResourceWithModifyPlan = &LocalFileResource{}
+var _ resource.ResourceWithImportState = &LocalFileResource{}
+var _ resource.ResourceWithUpgradeState = &LocalFileResource{}
 
 func NewLocalFileResource() resource.Resource {
 	return &LocalFileResource{}
 }
 
-type LocalFileResource struct{}
+type LocalFileResource struct {
+	config *localProviderConfig
+}
 
 func (r *LocalFileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_local_file"
@@ -50,8 +98,150 @@ func (r *LocalFileResource) Schema(_ context.Context, _ resource.SchemaRequest,
 	resp.Schema = LocalFileResourceSchema
 }
 
+func (r *LocalFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data LocalFileResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Permissions.IsNull() && !data.Permissions.IsUnknown() {
+		mode, err := parseFileMode(data.Permissions.ValueString(), defaultFileMode)
+		switch {
+		case err != nil:
+			resp.Diagnostics.AddAttributeError(path.Root("permissions"), "Invalid permissions", err.Error())
+		case mode&0400 == 0:
+			resp.Diagnostics.AddAttributeWarning(path.Root("permissions"), "File not readable by its owner", fmt.Sprintf("permissions %q result in mode %s, so the file will not be readable by its owner.", data.Permissions.ValueString(), formatFileMode(mode)))
+		}
+	}
+
+	if !data.DirectoryPermissions.IsNull() && !data.DirectoryPermissions.IsUnknown() {
+		if _, err := parseFileMode(data.DirectoryPermissions.ValueString(), defaultDirectoryMode); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("directory_permissions"), "Invalid directory permissions", err.Error())
+		}
+	}
+
+	if !data.ContentWoVersion.IsNull() && data.ContentWo.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("content_wo_version"), "Invalid content_wo_version", "content_wo_version can only be set together with content_wo.")
+	}
+
+	contents := []types.String{data.Content, data.ContentBase64, data.SensitiveContent, data.ContentWo}
+	set := 0
+	for _, content := range contents {
+		if content.IsUnknown() {
+			return
+		}
+		if !content.IsNull() {
+			set++
+		}
+	}
+	if set != 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("content"), "Invalid content", "Exactly one of content, content_base64, sensitive_content or content_wo must be set.")
+		return
+	}
+	if _, err := data.contentBytes(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid content_base64", err.Error())
+	}
+}
+
+func (r *LocalFileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan when the resource is being destroyed, but the path
+	// policy also applies to deleting the file
+	if req.Plan.Raw.IsNull() {
+		var state LocalFileResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if err := r.config.checkPath(state.Path.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+		}
+		return
+	}
+
+	var data LocalFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("content_wo"), &data.ContentWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !data.Path.IsUnknown() {
+		if err := r.config.checkPath(data.Path.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+			return
+		}
+	}
+
+	// Fail at plan time for unknown users and groups
+	if _, diags := data.ownership(); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		return
+	}
+
+	// Compute checksums at plan time, so that they can be referenced before apply.
+	// This is also how drift and changes are detected for write-only content.
+	if !data.Content.IsUnknown() && !data.ContentBase64.IsUnknown() && !data.SensitiveContent.IsUnknown() && !data.ContentWo.IsUnknown() {
+		content, err := data.contentBytes()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid content_base64", err.Error())
+			return
+		}
+		// The digest of secret content keeps its salt, so that it only changes
+		// with the content. Without a prior digest, a new salt is only chosen
+		// on apply, since Terraform plans again before applying.
+		var priorDigest types.String
+		if !req.State.Raw.IsNull() {
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("content_digest"), &priorDigest)...)
+		}
+		if data.secretContent() && priorDigest.IsNull() {
+			data.clearContentHashes()
+			data.ContentDigest = types.StringUnknown()
+		} else if err := data.setContentChecksums(content, priorDigest); err != nil {
+			resp.Diagnostics.AddError("Failed to compute content digest", err.Error())
+			return
+		}
+	}
+
+	// Directories are only created when the path changes
+	if !req.State.Raw.IsNull() {
+		var state LocalFileResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if data.Path.Equal(state.Path) {
+			data.CreatedDirectories = state.CreatedDirectories
+		}
+	}
+
+	// The ID is the absolute path, so it changes along with the path
+	data.Id = types.StringUnknown()
+	if !data.Path.IsUnknown() {
+		id, err := fileID(data.Path.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+			return
+		}
+		data.Id = types.StringValue(id)
+	}
+
+	data.ContentWo = types.StringNull()
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &data)...)
+}
+
 func (r *LocalFileResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// No configuration needed
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*localProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type", fmt.Sprintf("Expected *localProviderConfig, got: %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+	r.config = config
 }
 
 func (r *LocalFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -62,22 +252,83 @@ func (r *LocalFileResource) Create(ctx context.Context, req resource.CreateReque
 		return
 	}
 
-	// Generate a unique, stable ID before writing the file
-	data.Id = types.StringValue(generateFileID(data.Path.ValueString(), time.Now()))
+	// The path may have been unknown at plan time
+	if err := r.config.checkPath(data.Path.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+		return
+	}
 
-	// Create parent directories if they don't exist
-	dir := filepath.Dir(data.Path.ValueString())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		resp.Diagnostics.AddError("Failed to create directory", err.Error())
+	id, err := fileID(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+		return
+	}
+	data.Id = types.StringValue(id)
+
+	mode, err := parseFileMode(data.Permissions.ValueString(), defaultFileMode)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("permissions"), "Invalid permissions", err.Error())
+		return
+	}
+
+	// Write-only content is only available in the configuration
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("content_wo"), &data.ContentWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := data.contentBytes()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid content_base64", err.Error())
+		return
+	}
+
+	ownership, diags := data.ownership()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create missing parent directories, and remember them so that they can be removed on destroy
+	created, diags := data.createParentDirectories(ownership)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.CreatedDirectories, diags = types.ListValueFrom(ctx, types.StringType, created)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Write the file
-	if err := os.WriteFile(data.Path.ValueString(), []byte(data.Content.ValueString()), 0644); err != nil {
+	if err := writeLocalFile(data.Path.ValueString(), content, mode, ownership, data.Atomic.ValueBool()); err != nil {
+		var ownerErr *ownershipError
+		if errors.As(err, &ownerErr) {
+			resp.Diagnostics.AddError("Failed to change file ownership", err.Error())
+			return
+		}
 		resp.Diagnostics.AddError("Failed to write file", err.Error())
 		return
 	}
 
+	if err := data.setContentChecksums(content, data.ContentDigest); err != nil {
+		resp.Diagnostics.AddError("Failed to compute content digest", err.Error())
+		return
+	}
+	data.ContentWo = types.StringNull()
+
+	// Record the resulting ownership, which defaults to the user running Terraform
+	info, err := os.Stat(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", err.Error())
+		return
+	}
+	if err := data.setOwnership(info); err != nil {
+		resp.Diagnostics.AddError("Failed to read file ownership", err.Error())
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -89,17 +340,61 @@ func (r *LocalFileResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	content, err := os.ReadFile(data.Path.ValueString())
-	if err != nil {
-		if os.IsNotExist(err) {
-			resp.State.RemoveResource(ctx)
+	if data.secretContent() {
+		// Sensitive and write-only content is never read back, a changed
+		// digest alone causes the file to be rewritten
+		digest, err := fileDigest(data.Path.ValueString(), data.ContentDigest.ValueString())
+		if err != nil {
+			if os.IsNotExist(err) {
+				resp.State.RemoveResource(ctx)
+				return
+			}
+			resp.Diagnostics.AddError("Failed to read file", err.Error())
+			return
+		}
+		// Also drops checksums of secret content stored by earlier versions
+		data.clearContentHashes()
+		data.ContentDigest = types.StringValue(digest)
+	} else {
+		// Compare checksums rather than content, and only read the content when it has changed
+		hashes, err := hashFile(data.Path.ValueString())
+		if err != nil {
+			if os.IsNotExist(err) {
+				resp.State.RemoveResource(ctx)
+				return
+			}
+			resp.Diagnostics.AddError("Failed to read file", err.Error())
 			return
 		}
+
+		if hashes.SHA256 != data.ContentSha256.ValueString() {
+			content, err := os.ReadFile(data.Path.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError("Failed to read file", err.Error())
+				return
+			}
+			data.setContent(content)
+			data.setContentHashes(hashContent(content))
+		}
+	}
+
+	// Detect permission drift, keeping the configured notation if the mode still matches
+	info, err := os.Stat(data.Path.ValueString())
+	if err != nil {
 		resp.Diagnostics.AddError("Failed to read file", err.Error())
 		return
 	}
+	actual := fileModeBits(info.Mode())
+	if expected, err := parseFileMode(data.Permissions.ValueString(), defaultFileMode); err != nil || expected != actual {
+		data.Permissions = types.StringValue(formatFileMode(actual))
+	}
+
+	// Detect ownership drift
+	if err := data.setOwnership(info); err != nil {
+		resp.Diagnostics.AddError("Failed to read file ownership", err.Error())
+		return
+	}
 
-	data.Content = types.StringValue(string(content))
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -119,22 +414,85 @@ func (r *LocalFileResource) Update(ctx context.Context, req resource.UpdateReque
 		return
 	}
 
-	// Preserve the original ID from state
-	data.Id = state.Id
+	// The path may have been unknown at plan time
+	if err := r.config.checkPath(data.Path.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Path not allowed", err.Error())
+		return
+	}
 
-	// Create parent directories if they don't exist
-	dir := filepath.Dir(data.Path.ValueString())
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		resp.Diagnostics.AddError("Failed to create directory", err.Error())
+	id, err := fileID(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+		return
+	}
+	data.Id = types.StringValue(id)
+
+	mode, err := parseFileMode(data.Permissions.ValueString(), defaultFileMode)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("permissions"), "Invalid permissions", err.Error())
+		return
+	}
+
+	// Write-only content is only available in the configuration
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("content_wo"), &data.ContentWo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := data.contentBytes()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("content_base64"), "Invalid content_base64", err.Error())
+		return
+	}
+
+	ownership, diags := data.ownership()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create missing parent directories, keeping track of those created for a previous path
+	created, diags := data.createParentDirectories(ownership)
+	resp.Diagnostics.Append(diags...)
+	previous, diags := listValueToStrings(ctx, state.CreatedDirectories)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.CreatedDirectories, diags = types.ListValueFrom(ctx, types.StringType, append(previous, created...))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Write the file
-	if err := os.WriteFile(data.Path.ValueString(), []byte(data.Content.ValueString()), 0644); err != nil {
+	if err := writeLocalFile(data.Path.ValueString(), content, mode, ownership, data.Atomic.ValueBool()); err != nil {
+		var ownerErr *ownershipError
+		if errors.As(err, &ownerErr) {
+			resp.Diagnostics.AddError("Failed to change file ownership", err.Error())
+			return
+		}
 		resp.Diagnostics.AddError("Failed to update file", err.Error())
 		return
 	}
 
+	if err := data.setContentChecksums(content, data.ContentDigest); err != nil {
+		resp.Diagnostics.AddError("Failed to compute content digest", err.Error())
+		return
+	}
+	data.ContentWo = types.StringNull()
+
+	// Record the resulting ownership, which defaults to the user running Terraform
+	info, err := os.Stat(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read file", err.Error())
+		return
+	}
+	if err := data.setOwnership(info); err != nil {
+		resp.Diagnostics.AddError("Failed to read file ownership", err.Error())
+		return
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -155,6 +513,402 @@ func (r *LocalFileResource) Delete(ctx context.Context, req resource.DeleteReque
 	if err := os.Remove(data.Path.ValueString()); err != nil {
 		if !os.IsNotExist(err) {
 			resp.Diagnostics.AddError("Failed to delete file", err.Error())
+			return
+		}
+	}
+
+	// Remove the directories created for the file, innermost first, unless they are in use
+	created, diags := listValueToStrings(ctx, data.CreatedDirectories)
+	resp.Diagnostics.Append(diags...)
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := os.Remove(created[i]); err != nil {
+			if !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTEMPTY) && !errors.Is(err, syscall.EEXIST) {
+				resp.Diagnostics.AddWarning("Failed to remove directory", err.Error())
+			}
+			if !os.IsNotExist(err) {
+				break
+			}
+		}
+	}
+}
+
+// ImportState adopts an existing file, given by its path, reading its content,
+// permissions and ownership from disk. Text files are imported as content and
+// binary files as content_base64.
+func (r *LocalFileResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if err := r.config.checkPath(req.ID); err != nil {
+		resp.Diagnostics.AddError("Path not allowed", err.Error())
+		return
+	}
+
+	content, err := os.ReadFile(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import file", err.Error())
+		return
+	}
+	info, err := os.Stat(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import file", err.Error())
+		return
+	}
+
+	id, err := fileID(req.ID)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to import file", err.Error())
+		return
+	}
+
+	data := LocalFileResourceModel{
+		Id:                   types.StringValue(id),
+		Path:                 types.StringValue(req.ID),
+		Permissions:          types.StringValue(formatFileMode(fileModeBits(info.Mode()))),
+		DeleteOnDestroy:      types.BoolValue(true),
+		Atomic:               types.BoolValue(true),
+		CreateDirectories:    types.BoolValue(true),
+		DirectoryPermissions: types.StringValue("0755"),
+		CreatedDirectories:   types.ListNull(types.StringType),
+	}
+	if utf8.Valid(content) {
+		data.Content = types.StringValue(string(content))
+	} else {
+		data.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+	}
+	data.setContentHashes(hashContent(content))
+	if err := data.setOwnership(info); err != nil {
+		resp.Diagnostics.AddError("Failed to read file ownership", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// UpgradeState migrates state from version 0, which used IDs derived from a
+// timestamp, to the deterministic IDs used since version 1.
+func (r *LocalFileResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	schemaV0 := LocalFileResourceSchema
+	schemaV0.Version = 0
+
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schemaV0,
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var data LocalFileResourceModel
+				resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				id, err := fileID(data.Path.ValueString())
+				if err != nil {
+					resp.Diagnostics.AddAttributeError(path.Root("path"), "Invalid path", err.Error())
+					return
+				}
+				data.Id = types.StringValue(id)
+
+				// Attributes added since version 0 are null in its state, which
+				// would plan an update to their defaults and to the content checksums
+				data.Atomic = types.BoolValue(true)
+				data.CreateDirectories = types.BoolValue(true)
+				data.DirectoryPermissions = types.StringValue("0755")
+				data.CreatedDirectories = types.ListNull(types.StringType)
+				data.setContentHashes(hashContent([]byte(data.Content.ValueString())))
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+			},
+		},
+	}
+}
+
+// createDirectories creates dir and its missing parents with the given mode
+// and ownership, and returns the directories it created, outermost first. The
+// mode is set explicitly, since os.Mkdir is subject to the umask.
+func createDirectories(dir string, mode fs.FileMode, ownership fileOwnership) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	var created []string
+	for i := len(missing) - 1; i >= 0; i-- {
+		d := missing[i]
+		if err := os.Mkdir(d, mode.Perm()); err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return created, err
+		}
+		created = append(created, d)
+		if ownership.changes() {
+			if err := os.Chown(d, ownership.UID, ownership.GID); err != nil {
+				return created, &ownershipError{Path: d, Ownership: ownership, Err: err}
+			}
+		}
+		if err := os.Chmod(d, mode); err != nil {
+			return created, fmt.Errorf("failed to set permissions %s on %s: %w", formatFileMode(mode), d, err)
+		}
+	}
+	return created, nil
+}
+
+// writeLocalFile writes content to path and applies ownership and mode. The
+// mode is set explicitly, since os.WriteFile is subject to the umask and leaves
+// the mode of existing files unchanged. Ownership is changed before the mode,
+// since chown clears the setuid and setgid bits.
+func writeLocalFile(path string, content []byte, mode fs.FileMode, ownership fileOwnership, atomic bool) error {
+	if atomic {
+		return writeLocalFileAtomic(path, content, mode, ownership)
+	}
+	if err := os.WriteFile(path, content, mode.Perm()); err != nil {
+		return err
+	}
+	if ownership.changes() {
+		if err := os.Chown(path, ownership.UID, ownership.GID); err != nil {
+			return &ownershipError{Path: path, Ownership: ownership, Err: err}
+		}
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to set permissions %s: %w", formatFileMode(mode), err)
+	}
+	return nil
+}
+
+// writeLocalFileAtomic writes content to a temporary file in the same
+// directory as path, syncs it to disk and renames it over path. The directory
+// is synced as well, so that the rename survives a crash. If path is a
+// symlink, the file it points to is replaced and the link is kept, like a
+// write in place would.
+func writeLocalFileAtomic(path string, content []byte, mode fs.FileMode, ownership fileOwnership) (err error) {
+	path, err = resolveWriteTarget(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(content); err != nil {
+		return err
+	}
+	if ownership.changes() {
+		if err = tmp.Chown(ownership.UID, ownership.GID); err != nil {
+			return &ownershipError{Path: path, Ownership: ownership, Err: err}
+		}
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to set permissions %s: %w", formatFileMode(mode), err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// maxSymlinkDepth bounds the number of symlinks followed when resolving the
+// target of a write, matching the limit of the Linux kernel.
+const maxSymlinkDepth = 40
+
+// resolveWriteTarget follows symlinks at path to the file a write in place
+// would modify. Unlike filepath.EvalSymlinks, it also resolves links whose
+// target does not exist yet, since writing through them creates the target.
+func resolveWriteTarget(path string) (string, error) {
+	for i := 0; i < maxSymlinkDepth; i++ {
+		info, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return path, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return path, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+}
+
+// syncDir flushes directory entries, such as a rename, to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// createParentDirectories creates the missing parent directories of the file
+// according to create_directories and directory_permissions, and returns the
+// directories it created.
+func (m *LocalFileResourceModel) createParentDirectories(ownership fileOwnership) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	dir := filepath.Dir(m.Path.ValueString())
+
+	if !m.CreateDirectories.ValueBool() {
+		if _, err := os.Stat(dir); err != nil {
+			diags.AddAttributeError(path.Root("path"), "Parent directory does not exist", fmt.Sprintf("%s, and create_directories is false: %v", dir, err))
+		}
+		return nil, diags
+	}
+
+	mode, err := parseFileMode(m.DirectoryPermissions.ValueString(), defaultDirectoryMode)
+	if err != nil {
+		diags.AddAttributeError(path.Root("directory_permissions"), "Invalid directory permissions", err.Error())
+		return nil, diags
+	}
+
+	created, err := createDirectories(dir, mode, ownership)
+	if err != nil {
+		var ownerErr *ownershipError
+		if errors.As(err, &ownerErr) {
+			diags.AddError("Failed to change directory ownership", err.Error())
+		} else {
+			diags.AddError("Failed to create directory", err.Error())
 		}
 	}
+	return created, diags
+}
+
+// ownership resolves the owner and group attributes. Unset or unknown
+// attributes leave the corresponding ID unchanged.
+func (m *LocalFileResourceModel) ownership() (fileOwnership, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	ownership := unchangedOwnership
+
+	if !m.Owner.IsNull() && !m.Owner.IsUnknown() {
+		uid, err := lookupOwner(m.Owner.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("owner"), "Invalid owner", err.Error())
+		}
+		ownership.UID = uid
+	}
+	if !m.Group.IsNull() && !m.Group.IsUnknown() {
+		gid, err := lookupGroup(m.Group.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("group"), "Invalid group", err.Error())
+		}
+		ownership.GID = gid
+	}
+
+	return ownership, diags
+}
+
+// setOwnership records the owner and group of the file, keeping the
+// configured name or ID if it still matches.
+func (m *LocalFileResourceModel) setOwnership(info fs.FileInfo) error {
+	ownership, err := fileOwnershipOf(info)
+	if err != nil {
+		return err
+	}
+	if uid, err := lookupOwner(m.Owner.ValueString()); m.Owner.IsNull() || m.Owner.IsUnknown() || err != nil || uid != ownership.UID {
+		m.Owner = types.StringValue(formatOwner(ownership.UID))
+	}
+	if gid, err := lookupGroup(m.Group.ValueString()); m.Group.IsNull() || m.Group.IsUnknown() || err != nil || gid != ownership.GID {
+		m.Group = types.StringValue(formatGroup(ownership.GID))
+	}
+	return nil
+}
+
+// setContentHashes sets the content_* checksum attributes.
+func (m *LocalFileResourceModel) setContentHashes(hashes contentHashes) {
+	m.ContentMd5 = types.StringValue(hashes.MD5)
+	m.ContentSha1 = types.StringValue(hashes.SHA1)
+	m.ContentSha256 = types.StringValue(hashes.SHA256)
+	m.ContentSha512 = types.StringValue(hashes.SHA512)
+	m.ContentBase64Sha256 = types.StringValue(hashes.Base64SHA256)
+}
+
+// clearContentHashes clears the content_* checksum attributes.
+func (m *LocalFileResourceModel) clearContentHashes() {
+	m.ContentMd5 = types.StringNull()
+	m.ContentSha1 = types.StringNull()
+	m.ContentSha256 = types.StringNull()
+	m.ContentSha512 = types.StringNull()
+	m.ContentBase64Sha256 = types.StringNull()
+}
+
+// secretContent reports whether the content is given by sensitive_content or
+// content_wo, whose plain checksums are not exposed.
+func (m *LocalFileResourceModel) secretContent() bool {
+	return m.Content.IsNull() && m.ContentBase64.IsNull()
+}
+
+// setContentChecksums sets the content_* checksums of plain content, or the
+// content_digest of secret content. The digest reuses the salt of previous,
+// if it has one, so that it only changes along with the content.
+func (m *LocalFileResourceModel) setContentChecksums(content []byte, previous types.String) error {
+	if !m.secretContent() {
+		m.setContentHashes(hashContent(content))
+		m.ContentDigest = types.StringNull()
+		return nil
+	}
+	salt, err := digestSalt(previous.ValueString())
+	if err != nil {
+		return err
+	}
+	digest, err := contentDigest(salt, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	m.clearContentHashes()
+	m.ContentDigest = types.StringValue(digest)
+	return nil
+}
+
+// contentBytes returns the file content given by content, content_base64,
+// sensitive_content or content_wo.
+func (m *LocalFileResourceModel) contentBytes() ([]byte, error) {
+	switch {
+	case !m.ContentBase64.IsNull():
+		content, err := base64.StdEncoding.DecodeString(m.ContentBase64.ValueString())
+		if err != nil {
+			return nil, fmt.Errorf("content_base64 is not valid base64: %w", err)
+		}
+		return content, nil
+	case !m.SensitiveContent.IsNull():
+		return []byte(m.SensitiveContent.ValueString()), nil
+	case !m.ContentWo.IsNull():
+		return []byte(m.ContentWo.ValueString()), nil
+	}
+	return []byte(m.Content.ValueString()), nil
+}
+
+// setContent stores content read from disk in the attribute used by the configuration.
+func (m *LocalFileResourceModel) setContent(content []byte) {
+	if !m.ContentBase64.IsNull() {
+		m.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+		return
+	}
+	m.Content = types.StringValue(string(content))
 }
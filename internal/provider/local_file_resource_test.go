@@ -1,12 +1,26 @@
 package provider
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
 )
 
 func TestAccLocalFileResource(t *testing.T) {
@@ -27,7 +41,13 @@ func TestAccLocalFileResource(t *testing.T) {
 				Check: resource.ComposeAggregateTestCheckFunc(
 					// Basic file checks
 					resource.TestCheckResourceAttr("tf_local_file.test", "path", filepath.Join(tempDir, "test.txt")),
+					resource.TestCheckResourceAttr("tf_local_file.test", "id", filepath.Join(tempDir, "test.txt")),
 					resource.TestCheckResourceAttr("tf_local_file.test", "content", "hello world"),
+					resource.TestCheckResourceAttr("tf_local_file.test", "content_md5", "5eb63bbbe01eeed093cb22bb8f5acdc3"),
+					resource.TestCheckResourceAttr("tf_local_file.test", "content_sha1", "2aae6c35c94fcfb415dbe95f408b9ce91ee846ed"),
+					resource.TestCheckResourceAttr("tf_local_file.test", "content_sha256", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"),
+					resource.TestCheckResourceAttr("tf_local_file.test", "content_sha512", "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f"),
+					resource.TestCheckResourceAttr("tf_local_file.test", "content_base64sha256", "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="),
 
 					// File with permissions checks
 					resource.TestCheckResourceAttr("tf_local_file.test_perms", "path", filepath.Join(tempDir, "test_perms.txt")),
@@ -111,3 +131,985 @@ resource "tf_local_file" "test_write" {
 }
 `, tempDir, tempDir, tempDir, tempDir)
 }
+
+func TestAccLocalFileResource_Permissions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	secret := filepath.Join(tempDir, "secret.key")
+	symbolic := filepath.Join(tempDir, "symbolic.txt")
+
+	config := func(mode string) string {
+		return fmt.Sprintf(`
+resource "tf_local_file" "secret" {
+  path        = "%s"
+  content     = "key"
+  permissions = "%s"
+}
+
+resource "tf_local_file" "symbolic" {
+  path        = "%s"
+  content     = "symbolic"
+  permissions = "u=rw,go="
+}
+`, secret, mode, symbolic)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Invalid modes are rejected at plan time
+			{
+				Config:      config("0999"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`not a valid octal file mode`),
+			},
+			{
+				Config: config("0600"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileMode(secret, 0600),
+					testCheckFileMode(symbolic, 0600),
+					resource.TestCheckResourceAttr("tf_local_file.symbolic", "permissions", "u=rw,go="),
+				),
+			},
+			// Changing permissions updates the mode of the existing file
+			{
+				Config: config("0640"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileMode(secret, 0640),
+				),
+			},
+			// Changing the mode outside of Terraform is detected and corrected
+			{
+				PreConfig: func() {
+					if err := os.Chmod(secret, 0666); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config("0640"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.secret", plancheck.ResourceActionUpdate),
+						plancheck.ExpectResourceAction("tf_local_file.symbolic", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileMode(secret, 0640),
+				),
+			},
+		},
+	})
+}
+
+// testCheckFileMode checks the permission bits of a file on disk.
+func testCheckFileMode(path string, expected fs.FileMode) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if actual := fileModeBits(info.Mode()); actual != expected {
+			return fmt.Errorf("expected %s to have mode %s, got %s", path, formatFileMode(expected), formatFileMode(actual))
+		}
+		return nil
+	}
+}
+
+func TestAccLocalFileResource_Atomic(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	atomic := filepath.Join(tempDir, "atomic.conf")
+	inPlace := filepath.Join(tempDir, "in_place.conf")
+
+	config := func(content string) string {
+		return fmt.Sprintf(`
+resource "tf_local_file" "atomic" {
+  path    = "%s"
+  content = "%s"
+}
+
+resource "tf_local_file" "in_place" {
+  path    = "%s"
+  content = "%s"
+  atomic  = false
+}
+`, atomic, content, inPlace, content)
+	}
+
+	// A reader that opened the file before an update keeps seeing the old
+	// version when the file is replaced atomically
+	var reader *os.File
+	defer func() {
+		if reader != nil {
+			reader.Close()
+		}
+	}()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("version 1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_file.atomic", "atomic", "true"),
+					resource.TestCheckResourceAttr("tf_local_file.in_place", "atomic", "false"),
+					testCheckFileContent(atomic, "version 1"),
+					testCheckFileContent(inPlace, "version 1"),
+				),
+			},
+			{
+				PreConfig: func() {
+					if reader, err = os.Open(atomic); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config("version 2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(atomic, "version 2"),
+					testCheckFileContent(inPlace, "version 2"),
+					func(_ *terraform.State) error {
+						content, err := io.ReadAll(reader)
+						if err != nil {
+							return err
+						}
+						if string(content) != "version 1" {
+							return fmt.Errorf("expected open reader to see %q, got %q", "version 1", string(content))
+						}
+						return nil
+					},
+					// No temporary files are left behind
+					func(_ *terraform.State) error {
+						entries, err := os.ReadDir(tempDir)
+						if err != nil {
+							return err
+						}
+						if len(entries) != 2 {
+							return fmt.Errorf("expected 2 files in %s, got %d", tempDir, len(entries))
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func TestWriteLocalFileAtomic_Symlink(t *testing.T) {
+	tempDir := t.TempDir()
+	targetDir := filepath.Join(tempDir, "dotfiles")
+	if err := os.Mkdir(targetDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		exists bool
+	}{
+		{name: "absolute", target: filepath.Join(targetDir, "absolute.conf"), exists: true},
+		{name: "relative", target: filepath.Join("dotfiles", "relative.conf"), exists: true},
+		{name: "dangling", target: filepath.Join(targetDir, "dangling.conf")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := filepath.Join(tempDir, tt.name+".link")
+			if err := os.Symlink(tt.target, link); err != nil {
+				t.Fatal(err)
+			}
+			target := tt.target
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(tempDir, target)
+			}
+			if tt.exists {
+				if err := os.WriteFile(target, []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := writeLocalFile(link, []byte("new"), 0640, unchangedOwnership, true); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			info, err := os.Lstat(link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode()&fs.ModeSymlink == 0 {
+				t.Errorf("expected %s to remain a symlink, got mode %s", link, info.Mode())
+			}
+			content, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != "new" {
+				t.Errorf("expected target to contain %q, got %q", "new", string(content))
+			}
+			if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0640 {
+				t.Errorf("expected target permissions 0640, got %v (%v)", info.Mode().Perm(), err)
+			}
+		})
+	}
+
+	// No temporary files are left behind next to the targets
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(tests) {
+		t.Errorf("expected %d files in %s, got %d", len(tests), targetDir, len(entries))
+	}
+}
+
+func TestAccLocalFileResource_ContentDrift(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "artifact.txt")
+	config := fmt.Sprintf(`
+resource "tf_local_file" "artifact" {
+  path    = "%s"
+  content = "hello world"
+}
+
+# Checksums are known at plan time and can be referenced downstream
+resource "tf_local_exec" "checksum" {
+  command = "echo ${tf_local_file.artifact.content_sha256}"
+}
+`, file)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("tf_local_exec.checksum", tfjsonpath.New("command"), knownvalue.StringExact("echo b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.checksum", "output", "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9\n"),
+				),
+			},
+			// Content changed outside of Terraform is detected through its checksum
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte("tampered"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.artifact", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "hello world"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalFileResource_ContentBase64(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "image.bin")
+	// Not valid UTF-8, so it would be mangled if written through content
+	binary := []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0xff, 0xfe, 0x0a}
+	config := func(attrs string) string {
+		return fmt.Sprintf(`
+resource "tf_local_file" "image" {
+  path = "%s"
+  %s
+}
+`, file, attrs)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(`content = "a"` + "\n" + `content_base64 = "YQ=="`),
+				ExpectError: regexp.MustCompile(`Exactly one of content`),
+			},
+			{
+				Config:      config(`content_base64 = "not base64!"`),
+				ExpectError: regexp.MustCompile(`content_base64 is not valid base64`),
+			},
+			{
+				Config: config(`content_base64 = "iVBORwD//go="`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileBytes(file, binary),
+					resource.TestCheckNoResourceAttr("tf_local_file.image", "content"),
+					resource.TestCheckResourceAttr("tf_local_file.image", "content_sha256", hashContent(binary).SHA256),
+				),
+			},
+			// Binary content changed outside of Terraform is detected and restored
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte{0x00, 0x01}, 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config(`content_base64 = "iVBORwD//go="`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.image", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckFileBytes(file, binary),
+			},
+			// Switching to content writes the text as-is
+			{
+				Config: config(`content = "text"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileBytes(file, []byte("text")),
+					resource.TestCheckNoResourceAttr("tf_local_file.image", "content_base64"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckFileBytes(path string, expected []byte) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		actual, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(actual, expected) {
+			return fmt.Errorf("expected %s to contain %x, got %x", path, expected, actual)
+		}
+		return nil
+	}
+}
+
+func TestAccLocalFileResource_SensitiveContent(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "credentials")
+	config := fmt.Sprintf(`
+resource "tf_local_file" "credentials" {
+  path              = "%s"
+  sensitive_content = "s3cr3t"
+  permissions       = "0600"
+}
+`, file)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "s3cr3t"),
+					resource.TestCheckNoResourceAttr("tf_local_file.credentials", "content"),
+					// Plain checksums of secret content are not exposed
+					resource.TestCheckNoResourceAttr("tf_local_file.credentials", "content_sha256"),
+					resource.TestCheckNoResourceAttr("tf_local_file.credentials", "content_md5"),
+					resource.TestCheckResourceAttrSet("tf_local_file.credentials", "content_digest"),
+				),
+			},
+			// Drift is detected through the digest, without reading the file into state
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte("tampered"), 0600); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.credentials", plancheck.ResourceActionUpdate),
+						plancheck.ExpectSensitiveValue("tf_local_file.credentials", tfjsonpath.New("content_digest")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "s3cr3t"),
+					resource.TestCheckResourceAttr("tf_local_file.credentials", "sensitive_content", "s3cr3t"),
+				),
+			},
+		},
+	})
+}
+
+func TestContentDigest(t *testing.T) {
+	salt, err := digestSalt("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := contentDigest(salt, strings.NewReader("s3cr3t"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(digest, hashContent([]byte("s3cr3t")).SHA256) {
+		t.Errorf("expected digest %q not to contain the plain SHA256 of the content", digest)
+	}
+
+	// The salt is reused, so the digest only changes along with the content
+	reused, err := digestSalt(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := contentDigest(reused, strings.NewReader("s3cr3t")); again != digest {
+		t.Errorf("expected the same digest with the same salt, got %q and %q", digest, again)
+	}
+	if changed, _ := contentDigest(reused, strings.NewReader("tampered")); changed == digest {
+		t.Errorf("expected a different digest for different content")
+	}
+
+	// Without a previous digest, every salt is new
+	other, err := digestSalt("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(salt, other) {
+		t.Errorf("expected new salts to differ")
+	}
+}
+
+func TestAccLocalFileResource_ContentWriteOnly(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "token")
+	config := func(content string, version int) string {
+		return fmt.Sprintf(`
+resource "tf_local_file" "token" {
+  path               = "%s"
+  content_wo         = "%s"
+  content_wo_version = %d
+}
+`, file, content, version)
+	}
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			// Write-only attributes were introduced in Terraform 1.11
+			tfversion.SkipBelow(version.Must(version.NewVersion("1.11.0"))),
+		},
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "tf_local_file" "token" {
+  path               = "%s"
+  content            = "a"
+  content_wo_version = 1
+}
+`, file),
+				ExpectError: regexp.MustCompile(`content_wo_version can only be set together with content_wo`),
+			},
+			{
+				Config: config("first", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "first"),
+					resource.TestCheckNoResourceAttr("tf_local_file.token", "content_wo"),
+					resource.TestCheckNoResourceAttr("tf_local_file.token", "content_sha256"),
+					resource.TestCheckResourceAttrSet("tf_local_file.token", "content_digest"),
+				),
+			},
+			// Changed write-only content is detected through its digest
+			{
+				Config: config("second", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.token", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckFileContent(file, "second"),
+			},
+			// Drift on disk is detected through the digest as well
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(file, []byte("tampered"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config("second", 1),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.token", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckFileContent(file, "second"),
+			},
+			// Bumping the version forces a rewrite
+			{
+				Config: config("second", 2),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.token", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "second"),
+					resource.TestCheckResourceAttr("tf_local_file.token", "content_wo_version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalFileResource_Ownership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing file ownership requires root")
+	}
+
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	file := filepath.Join(tempDir, "service.conf")
+	config := func(owner, group string) string {
+		return fmt.Sprintf(`
+resource "tf_local_file" "service" {
+  path    = "%s"
+  content = "listen = 8080"
+  owner   = "%s"
+  group   = "%s"
+}
+`, file, owner, group)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("no-such-user", "0"),
+				ExpectError: regexp.MustCompile(`unknown user "no-such-user"`),
+			},
+			{
+				Config: config("4242", "4343"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileOwnership(file, 4242, 4343),
+					resource.TestCheckResourceAttr("tf_local_file.service", "owner", "4242"),
+				),
+			},
+			// Ownership changed outside of Terraform is detected and restored
+			{
+				PreConfig: func() {
+					if err := os.Chown(file, 0, 0); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: config("4242", "4343"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.service", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: testCheckFileOwnership(file, 4242, 4343),
+			},
+			// Owners and groups can be given by name as well
+			{
+				Config: config("root", "root"),
+				Check:  testCheckFileOwnership(file, 0, 0),
+			},
+		},
+	})
+}
+
+func testCheckFileOwnership(path string, uid, gid int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		ownership, err := fileOwnershipOf(info)
+		if err != nil {
+			return err
+		}
+		if ownership.UID != uid || ownership.GID != gid {
+			return fmt.Errorf("expected %s to be owned by %d:%d, got %d:%d", path, uid, gid, ownership.UID, ownership.GID)
+		}
+		return nil
+	}
+}
+
+func TestAccLocalFileResource_Directories(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outer := filepath.Join(tempDir, "secrets")
+	inner := filepath.Join(outer, "app")
+	file := filepath.Join(inner, "token")
+	unrelated := filepath.Join(outer, "unrelated")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// Created directories are removed on destroy, unless they are still in use
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testCheckFileExists(unrelated),
+			func(_ *terraform.State) error {
+				if _, err := os.Stat(inner); !os.IsNotExist(err) {
+					return fmt.Errorf("expected %s to be removed, got %v", inner, err)
+				}
+				return nil
+			},
+		),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "tf_local_file" "token" {
+  path               = "%s"
+  content            = "token"
+  create_directories = false
+}
+`, file),
+				ExpectError: regexp.MustCompile(`Parent directory does not exist`),
+			},
+			{
+				Config: fmt.Sprintf(`
+resource "tf_local_file" "token" {
+  path                  = "%s"
+  content               = "token"
+  permissions           = "0600"
+  directory_permissions = "0700"
+}
+`, file),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testCheckFileContent(file, "token"),
+					testCheckFileMode(outer, 0700),
+					testCheckFileMode(inner, 0700),
+					resource.TestCheckResourceAttr("tf_local_file.token", "created_directories.#", "2"),
+					resource.TestCheckResourceAttr("tf_local_file.token", "created_directories.0", outer),
+					resource.TestCheckResourceAttr("tf_local_file.token", "created_directories.1", inner),
+				),
+			},
+			// Another file in a created directory keeps it from being removed
+			{
+				PreConfig: func() {
+					if err := os.WriteFile(unrelated, []byte("keep"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: fmt.Sprintf(`
+resource "tf_local_file" "token" {
+  path                  = "%s"
+  content               = "rotated"
+  permissions           = "0600"
+  directory_permissions = "0700"
+}
+`, file),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("tf_local_file.token", tfjsonpath.New("created_directories"), knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact(outer), knownvalue.StringExact(inner)})),
+					},
+				},
+				Check: testCheckFileContent(file, "rotated"),
+			},
+		},
+	})
+}
+
+func TestAccLocalFileResource_Import(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	text := filepath.Join(tempDir, "app.conf")
+	if err := os.WriteFile(text, []byte("port = 80\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(text, 0640); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(tempDir, "image.bin")
+	if err := os.WriteFile(binary, []byte{0x89, 0x50, 0x4e, 0x47, 0x00, 0xff, 0xfe, 0x0a}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := fmt.Sprintf(`
+import {
+  to = tf_local_file.app
+  id = "%[1]s"
+}
+
+resource "tf_local_file" "app" {
+  path        = "%[1]s"
+  content     = "port = 80\n"
+  permissions = "0640"
+}
+
+import {
+  to = tf_local_file.image
+  id = "%[2]s"
+}
+
+resource "tf_local_file" "image" {
+  path           = "%[2]s"
+  content_base64 = "iVBORwD//go="
+}
+`, text, binary)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Existing files are adopted without changes
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("tf_local_file.app", plancheck.ResourceActionNoop),
+						plancheck.ExpectResourceAction("tf_local_file.image", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_file.app", "content", "port = 80\n"),
+					resource.TestCheckResourceAttr("tf_local_file.app", "permissions", "0640"),
+					resource.TestCheckResourceAttr("tf_local_file.app", "owner", formatOwner(os.Getuid())),
+					resource.TestCheckResourceAttr("tf_local_file.app", "group", formatGroup(os.Getgid())),
+					resource.TestCheckNoResourceAttr("tf_local_file.image", "content"),
+					resource.TestCheckResourceAttr("tf_local_file.image", "content_base64", "iVBORwD//go="),
+				),
+			},
+			{
+				ResourceName:      "tf_local_file.app",
+				ImportState:       true,
+				ImportStateId:     text,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccLocalFileResource_PathPolicy(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "test-dir-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	allowed := filepath.Join(tempDir, "allowed")
+	outside := filepath.Join(tempDir, "outside")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	providerConfig := fmt.Sprintf(`
+provider "tf" {
+  allowed_paths = ["%[1]s"]
+  denied_paths  = ["%[1]s/**/*.key"]
+}
+`, allowed)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Violations fail at plan time and name the rule
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_file" "test" {
+  path    = "%s/file.txt"
+  content = "content"
+}
+`, outside),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`not matched by any\s+allowed_paths\s+rule`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_file" "test" {
+  path    = "%s/certs/server.key"
+  content = "content"
+}
+`, allowed),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`denied by\s+denied_paths\s+rule`),
+			},
+			// Symlinks are resolved before matching
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_file" "test" {
+  path    = "%s/link/file.txt"
+  content = "content"
+}
+`, allowed),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`resolves to`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_file" "test" {
+  path    = "%s/../outside/file.txt"
+  content = "content"
+}
+`, allowed),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`must\s+not\s+contain\s+"\.\."`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+data "tf_local_file" "test" {
+  path = "%s/file.txt"
+}
+`, outside),
+				ExpectError: regexp.MustCompile(`not matched by any\s+allowed_paths\s+rule`),
+			},
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "tf_local_file" "test" {
+  path    = "%s/config/app.conf"
+  content = "content"
+}
+`, allowed),
+				Check: testCheckFileContent(filepath.Join(allowed, "config", "app.conf"), "content"),
+			},
+		},
+	})
+}
+
+func TestLocalFileResource_UpgradeState(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := testAccProtoV6ProviderFactories["tf"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// State written by version 0, with a timestamp-based ID
+	resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "tf_local_file",
+		Version:  0,
+		RawState: &tfprotov6.RawState{JSON: []byte(`{"path": "config/app.json", "content": "{}", "permissions": "0644", "fail_if_absent": null, "delete_on_destroy": true, "id": "178e0475d40fb383cba4dbbb9312cd21"}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics[0])
+	}
+
+	expected, err := filepath.Abs("config/app.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := LocalFileResourceSchema.Type().TerraformType(ctx)
+	testCheckUpgradedID(t, resp.UpgradedState, typ, expected)
+
+	testCheckUpgradedStatePlansNoChanges(t, server, "tf_local_file", typ, resp.UpgradedState, map[string]tftypes.Value{
+		"path":    tftypes.NewValue(tftypes.String, "config/app.json"),
+		"content": tftypes.NewValue(tftypes.String, "{}"),
+	})
+}
+
+func testCheckUpgradedID(t *testing.T, state *tfprotov6.DynamicValue, typ tftypes.Type, expected string) {
+	t.Helper()
+
+	value, err := state.Unmarshal(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := value.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+	var id string
+	if err := attrs["id"].As(&id); err != nil {
+		t.Fatal(err)
+	}
+	if id != expected {
+		t.Errorf("expected id %q, got %q", expected, id)
+	}
+}
+
+// testCheckUpgradedStatePlansNoChanges plans the upgraded state against a
+// configuration that only sets the given attributes, and checks that the plan
+// leaves the state unchanged.
+func testCheckUpgradedStatePlansNoChanges(t *testing.T, server tfprotov6.ProviderServer, typeName string, typ tftypes.Type, state *tfprotov6.DynamicValue, configured map[string]tftypes.Value) {
+	t.Helper()
+	ctx := context.Background()
+
+	objectType := typ.(tftypes.Object)
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+	}
+	for name, value := range configured {
+		attrs[name] = value
+	}
+	config, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, attrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Terraform proposes the prior state, since the configuration matches it
+	resp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       state,
+		ProposedNewState: state,
+		Config:           &config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics[0])
+	}
+	if len(resp.RequiresReplace) > 0 {
+		t.Errorf("expected no replacement, got %v", resp.RequiresReplace)
+	}
+
+	prior, err := state.Unmarshal(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned, err := resp.PlannedState.Unmarshal(typ)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := prior.Diff(planned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range diffs {
+		t.Errorf("unexpected change at %s: %s -> %s", diff.Path, diff.Value1, diff.Value2)
+	}
+}
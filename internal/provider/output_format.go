@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported values of the output_format attribute.
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+	outputFormatYAML = "yaml"
+)
+
+var outputFormats = []string{outputFormatText, outputFormatJSON, outputFormatYAML}
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages.
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// outputParseError describes where command output failed to parse. JSON
+// errors are located by byte offset; YAML errors by line, and by column when
+// it is known, with Offset set to the corresponding byte for the excerpt.
+type outputParseError struct {
+	Format string
+	Offset int64
+	Line   int
+	Column int
+	Input  string
+	Err    error
+}
+
+func (e *outputParseError) Error() string {
+	position := fmt.Sprintf("byte offset %d", e.Offset)
+	if e.Line > 0 {
+		position = fmt.Sprintf("line %d", e.Line)
+		if e.Column > 0 {
+			position += fmt.Sprintf(", column %d", e.Column)
+		}
+	}
+	return fmt.Sprintf("stdout is not valid %s at %s: %v\n\nNear: %q", strings.ToUpper(e.Format), position, e.Err, excerpt(e.Input, e.Offset))
+}
+
+// validateOutputFormat checks that output_format is one of the supported formats.
+func validateOutputFormat(value types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if value.IsNull() || value.IsUnknown() {
+		return diags
+	}
+	for _, format := range outputFormats {
+		if value.ValueString() == format {
+			return diags
+		}
+	}
+	diags.AddAttributeError(path.Root("output_format"), "Invalid output format", fmt.Sprintf("output_format must be one of %s, got %q.", strings.Join(outputFormats, ", "), value.ValueString()))
+	return diags
+}
+
+// parseCommandOutput parses stdout according to the output format. Text output
+// is not parsed and yields a null result.
+func parseCommandOutput(ctx context.Context, format string, stdout string) (types.Dynamic, error) {
+	var value interface{}
+
+	switch format {
+	case "", outputFormatText:
+		return types.DynamicNull(), nil
+	case outputFormatJSON:
+		decoder := json.NewDecoder(strings.NewReader(stdout))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return types.DynamicNull(), &outputParseError{Format: format, Offset: jsonErrorOffset(err, decoder, stdout), Input: stdout, Err: err}
+		}
+		// Only whitespace may follow the JSON value
+		if _, err := decoder.Token(); err != io.EOF {
+			offset := decoder.InputOffset()
+			return types.DynamicNull(), &outputParseError{Format: format, Offset: offset, Input: stdout, Err: errors.New("unexpected data after top-level value")}
+		}
+	case outputFormatYAML:
+		decoder := yaml.NewDecoder(strings.NewReader(stdout))
+		// Empty output is a null value, as with yaml.Unmarshal
+		if err := decoder.Decode(&value); err != nil && err != io.EOF {
+			return types.DynamicNull(), yamlParseError(stdout, yamlErrorLine(err), 0, err)
+		}
+		// Later documents would otherwise be silently dropped
+		var next yaml.Node
+		if err := decoder.Decode(&next); err != io.EOF {
+			if err != nil {
+				return types.DynamicNull(), yamlParseError(stdout, yamlErrorLine(err), 0, err)
+			}
+			return types.DynamicNull(), yamlParseError(stdout, next.Line, next.Column, errors.New("multiple YAML documents are not supported"))
+		}
+	default:
+		return types.DynamicNull(), fmt.Errorf("unsupported output format %q", format)
+	}
+
+	result, err := attrValueFromGo(ctx, value)
+	if errors.Is(err, errNonFiniteNumber) {
+		line, column := yamlNonFinitePosition(stdout)
+		return types.DynamicNull(), yamlParseError(stdout, line, column, err)
+	}
+	if err != nil {
+		return types.DynamicNull(), err
+	}
+	return types.DynamicValue(result), nil
+}
+
+// jsonErrorOffset returns the byte offset at which decoding failed.
+func jsonErrorOffset(err error, decoder *json.Decoder, input string) int64 {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return int64(len(input))
+	}
+	return decoder.InputOffset()
+}
+
+// yamlParseError returns an outputParseError for YAML input at a 1-based line
+// and column. A zero line or column means it is unknown.
+func yamlParseError(input string, line, column int, err error) *outputParseError {
+	offset := lineOffset(input, line)
+	if column > 0 {
+		offset += int64(column - 1)
+	}
+	return &outputParseError{Format: outputFormatYAML, Offset: offset, Line: line, Column: column, Input: input, Err: err}
+}
+
+// yamlErrorLine returns the line number reported by a yaml.v3 error, which
+// does not include the column, or 0 if it has none.
+func yamlErrorLine(err error) int {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	line, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return 0
+	}
+	return line
+}
+
+// yamlNonFinitePosition returns the line and column of the first .nan or .inf
+// scalar in the input, which Terraform numbers cannot represent.
+func yamlNonFinitePosition(input string) (int, int) {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(input), &root); err != nil {
+		return 0, 0
+	}
+	node := findNonFiniteNode(&root)
+	if node == nil {
+		return 0, 0
+	}
+	return node.Line, node.Column
+}
+
+func findNonFiniteNode(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!float" {
+		var f float64
+		if err := node.Decode(&f); err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			return node
+		}
+	}
+	for _, child := range node.Content {
+		if found := findNonFiniteNode(child); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// lineOffset returns the byte offset of the start of a 1-based line.
+func lineOffset(input string, line int) int64 {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(input[offset:], '\n')
+		if next < 0 {
+			return int64(len(input))
+		}
+		offset += next + 1
+	}
+	return int64(offset)
+}
+
+// excerpt returns a short part of the input around the offset, for error messages.
+func excerpt(input string, offset int64) string {
+	const radius = 20
+	start := int(offset) - radius
+	if start < 0 {
+		start = 0
+	}
+	end := int(offset) + radius
+	if end > len(input) {
+		end = len(input)
+	}
+	if start > end {
+		return ""
+	}
+	return input[start:end]
+}
+
+// errNonFiniteNumber is returned for NaN and infinite numbers, which YAML can
+// express but Terraform cannot.
+var errNonFiniteNumber = errors.New("NaN and infinite numbers are not supported")
+
+// attrValueFromGo converts a decoded JSON or YAML value to a Terraform value.
+// Objects become object values and arrays become tuples, mirroring jsondecode.
+func attrValueFromGo(ctx context.Context, value interface{}) (attr.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return types.StringNull(), nil
+	case string:
+		return types.StringValue(v), nil
+	case bool:
+		return types.BoolValue(v), nil
+	case json.Number:
+		f, _, err := big.ParseFloat(v.String(), 10, 512, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s: %v", v, err)
+		}
+		return types.NumberValue(f), nil
+	case int:
+		return types.NumberValue(new(big.Float).SetInt64(int64(v))), nil
+	case int64:
+		return types.NumberValue(new(big.Float).SetInt64(v)), nil
+	case uint64:
+		return types.NumberValue(new(big.Float).SetUint64(v)), nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errNonFiniteNumber
+		}
+		return types.NumberValue(big.NewFloat(v)), nil
+	case time.Time:
+		return types.StringValue(v.Format(time.RFC3339Nano)), nil
+	case []interface{}:
+		elemTypes := make([]attr.Type, 0, len(v))
+		elems := make([]attr.Value, 0, len(v))
+		for _, item := range v {
+			elem, err := attrValueFromGo(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			elemTypes = append(elemTypes, elem.Type(ctx))
+			elems = append(elems, elem)
+		}
+		tuple, diags := types.TupleValue(elemTypes, elems)
+		if diags.HasError() {
+			return nil, fmt.Errorf("failed to convert array: %v", diags)
+		}
+		return tuple, nil
+	case map[string]interface{}:
+		return objectValueFromGo(ctx, v)
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = item
+		}
+		return objectValueFromGo(ctx, converted)
+	default:
+		return nil, fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func objectValueFromGo(ctx context.Context, v map[string]interface{}) (attr.Value, error) {
+	attrTypes := make(map[string]attr.Type, len(v))
+	attrs := make(map[string]attr.Value, len(v))
+	for key, item := range v {
+		elem, err := attrValueFromGo(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		attrTypes[key] = elem.Type(ctx)
+		attrs[key] = elem
+	}
+	object, diags := types.ObjectValue(attrTypes, attrs)
+	if diags.HasError() {
+		return nil, fmt.Errorf("failed to convert object: %v", diags)
+	}
+	return object, nil
+}
+
+// commandResultValue parses the stdout of a successful command according to
+// output_format. Commands that exited with a non-zero code yield a null result.
+func commandResultValue(ctx context.Context, format types.String, result localCommandResult) (types.Dynamic, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if result.ExitCode != 0 {
+		return types.DynamicNull(), diags
+	}
+	value, err := parseCommandOutput(ctx, format.ValueString(), result.Stdout)
+	if err != nil {
+		diags.AddAttributeError(path.Root("output_format"), "Failed to parse command output", err.Error())
+	}
+	return value, diags
+}
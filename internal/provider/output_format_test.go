@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestParseCommandOutput(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		expected attr.Value
+	}{
+		{
+			name:     "text",
+			format:   outputFormatText,
+			input:    "hello",
+			expected: types.DynamicNull(),
+		},
+		{
+			name:   "json object",
+			format: outputFormatJSON,
+			input:  `{"name": "app", "replicas": 3, "enabled": true, "tags": ["a", 1]}` + "\n",
+			expected: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"name":     types.StringType,
+					"replicas": types.NumberType,
+					"enabled":  types.BoolType,
+					"tags":     types.TupleType{ElemTypes: []attr.Type{types.StringType, types.NumberType}},
+				},
+				map[string]attr.Value{
+					"name":     types.StringValue("app"),
+					"replicas": types.NumberValue(big.NewFloat(3)),
+					"enabled":  types.BoolValue(true),
+					"tags": types.TupleValueMust(
+						[]attr.Type{types.StringType, types.NumberType},
+						[]attr.Value{types.StringValue("a"), types.NumberValue(big.NewFloat(1))},
+					),
+				},
+			)),
+		},
+		{
+			name:     "json string",
+			format:   outputFormatJSON,
+			input:    `"value"`,
+			expected: types.DynamicValue(types.StringValue("value")),
+		},
+		{
+			name:   "yaml",
+			format: outputFormatYAML,
+			input:  "name: app\nports:\n  - 80\n",
+			expected: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"name":  types.StringType,
+					"ports": types.TupleType{ElemTypes: []attr.Type{types.NumberType}},
+				},
+				map[string]attr.Value{
+					"name":  types.StringValue("app"),
+					"ports": types.TupleValueMust([]attr.Type{types.NumberType}, []attr.Value{types.NumberValue(big.NewFloat(80))}),
+				},
+			)),
+		},
+		{
+			name:     "yaml single document with marker",
+			format:   outputFormatYAML,
+			input:    "---\nname: app\n...\n",
+			expected: types.DynamicValue(types.ObjectValueMust(map[string]attr.Type{"name": types.StringType}, map[string]attr.Value{"name": types.StringValue("app")})),
+		},
+		{
+			name:     "yaml empty",
+			format:   outputFormatYAML,
+			input:    "",
+			expected: types.DynamicValue(types.StringNull()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseCommandOutput(ctx, tt.format, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseCommandOutput_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		offset   int64
+		position string
+	}{
+		{name: "json syntax error", format: outputFormatJSON, input: `{"a": 1,}`, offset: 9, position: "byte offset 9"},
+		{name: "json truncated", format: outputFormatJSON, input: `{"a": `, offset: 6, position: "byte offset 6"},
+		{name: "json trailing data", format: outputFormatJSON, input: `{"a": 1} {"b": 2}`, offset: 10, position: "byte offset 10"},
+		{name: "yaml error on third line", format: outputFormatYAML, input: "a: 1\nb: 2\nc: [\n", offset: 10, position: "line 3:"},
+		{name: "yaml nan", format: outputFormatYAML, input: "a: .nan\n", offset: 3, position: "line 1, column 4"},
+		{name: "yaml infinity", format: outputFormatYAML, input: "a: 1\nb: [2, -.inf]\n", offset: 12, position: "line 2, column 8"},
+		{name: "yaml infinity at top level", format: outputFormatYAML, input: ".inf", offset: 0, position: "line 1, column 1"},
+		{name: "yaml multiple documents", format: outputFormatYAML, input: "a: 1\n---\nb: 2\n", offset: 5, position: "line 2, column 1"},
+		{name: "yaml error in second document", format: outputFormatYAML, input: "a: 1\n---\nb: [\n", offset: 9, position: "line 3:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCommandOutput(ctx, tt.format, tt.input)
+			var parseErr *outputParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected outputParseError, got %v", err)
+			}
+			if parseErr.Offset != tt.offset {
+				t.Errorf("expected offset %d, got %d (%v)", tt.offset, parseErr.Offset, err)
+			}
+			if !strings.Contains(err.Error(), "at "+tt.position) {
+				t.Errorf("expected error to mention %q, got %q", tt.position, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pathPolicy restricts the paths that tf_local_file may read and write. A
+// path is allowed if it matches one of the allowed patterns, or if there are
+// none, and it matches none of the denied patterns. A pattern matches a path
+// if it matches the path itself or one of its parent directories, so a
+// pattern naming a directory covers everything beneath it.
+type pathPolicy struct {
+	Allowed []pathRule
+	Denied  []pathRule
+}
+
+// pathRule is a glob pattern of a path policy. Patterns support *, ? and
+// character classes within a path element, and ** across path elements.
+type pathRule struct {
+	Pattern string
+	regexp  *regexp.Regexp
+}
+
+// pathPolicyError describes why a path is not allowed by the policy.
+type pathPolicyError struct {
+	Path   string
+	Reason string
+}
+
+func (e *pathPolicyError) Error() string {
+	return fmt.Sprintf("%s is not allowed: %s", e.Path, e.Reason)
+}
+
+// newPathPolicy compiles the allowed_paths and denied_paths patterns. Relative
+// patterns are resolved against the working directory and a leading ~ is
+// expanded to the home directory.
+func newPathPolicy(allowed []string, denied []string) (pathPolicy, error) {
+	var policy pathPolicy
+	for _, pattern := range allowed {
+		rule, err := newPathRule(pattern)
+		if err != nil {
+			return policy, err
+		}
+		policy.Allowed = append(policy.Allowed, rule)
+	}
+	for _, pattern := range denied {
+		rule, err := newPathRule(pattern)
+		if err != nil {
+			return policy, err
+		}
+		policy.Denied = append(policy.Denied, rule)
+	}
+	return policy, nil
+}
+
+func newPathRule(pattern string) (pathRule, error) {
+	expanded := pattern
+	if expanded == "~" || strings.HasPrefix(expanded, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return pathRule{}, fmt.Errorf("failed to expand %q: %w", pattern, err)
+		}
+		expanded = home + expanded[1:]
+	}
+	expanded, err := filepath.Abs(expanded)
+	if err != nil {
+		return pathRule{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	// Resolve symlinks in the literal part of the pattern, so that it matches
+	// the resolved paths it is compared against
+	literal, rest := splitGlobPattern(expanded)
+	if resolved, err := filepath.EvalSymlinks(literal); err == nil {
+		expanded = filepath.Join(resolved, rest)
+	}
+
+	re, err := globRegexp(expanded)
+	if err != nil {
+		return pathRule{}, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return pathRule{Pattern: pattern, regexp: re}, nil
+}
+
+// splitGlobPattern splits an absolute pattern into the directory before the
+// first element containing a glob character, and the remaining elements.
+func splitGlobPattern(pattern string) (string, string) {
+	elements := strings.Split(pattern, string(filepath.Separator))
+	for i, element := range elements {
+		if strings.ContainsAny(element, "*?[") {
+			return strings.Join(elements[:i], string(filepath.Separator)), strings.Join(elements[i:], string(filepath.Separator))
+		}
+	}
+	return pattern, ""
+}
+
+// globRegexp converts a glob pattern to an anchored regular expression.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// matches reports whether the rule matches path or one of its parent directories.
+func (r pathRule) matches(path string) bool {
+	for {
+		if r.regexp.MatchString(path) {
+			return true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+func (p pathPolicy) enabled() bool {
+	return len(p.Allowed) > 0 || len(p.Denied) > 0
+}
+
+// check returns a *pathPolicyError if the policy does not allow path. The
+// path is checked after resolving symlinks, and paths containing ".." are
+// rejected, since the kernel resolves ".." after following symlinks while
+// cleaning the path lexically does not.
+func (p pathPolicy) check(path string) error {
+	if !p.enabled() {
+		return nil
+	}
+
+	for _, element := range strings.Split(filepath.ToSlash(path), "/") {
+		if element == ".." {
+			return &pathPolicyError{Path: path, Reason: `paths must not contain ".." when allowed_paths or denied_paths is set`}
+		}
+	}
+
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	// Follow a symlink at the path itself even if it dangles, since writing
+	// through it creates its target
+	target, err := resolveWriteTarget(absolute)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveSymlinks(target)
+	if err != nil {
+		return err
+	}
+
+	// Deny rules apply to the path as given as well as to where it leads
+	for _, rule := range p.Denied {
+		if rule.matches(absolute) || rule.matches(resolved) {
+			return &pathPolicyError{Path: path, Reason: fmt.Sprintf("denied by denied_paths rule %q", rule.Pattern)}
+		}
+	}
+
+	if len(p.Allowed) == 0 {
+		return nil
+	}
+	for _, rule := range p.Allowed {
+		if rule.matches(resolved) {
+			return nil
+		}
+	}
+	patterns := make([]string, len(p.Allowed))
+	for i, rule := range p.Allowed {
+		patterns[i] = fmt.Sprintf("%q", rule.Pattern)
+	}
+	reason := fmt.Sprintf("not matched by any allowed_paths rule (%s)", strings.Join(patterns, ", "))
+	if resolved != absolute {
+		reason = fmt.Sprintf("resolves to %s, which is %s", resolved, reason)
+	}
+	return &pathPolicyError{Path: path, Reason: reason}
+}
+
+// resolveSymlinks resolves symlinks in an absolute path whose last elements
+// may not exist yet, by resolving its longest existing prefix.
+func resolveSymlinks(path string) (string, error) {
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, missing...)...), nil
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
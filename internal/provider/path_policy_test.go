@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPathRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "/srv/app", path: "/srv/app", expected: true},
+		// A pattern naming a directory covers everything beneath it
+		{pattern: "/srv/app", path: "/srv/app/config/app.yaml", expected: true},
+		{pattern: "/srv/app", path: "/srv/application", expected: false},
+		{pattern: "/srv/*/config", path: "/srv/app/config/app.yaml", expected: true},
+		{pattern: "/srv/*.yaml", path: "/srv/app/config.yaml", expected: false},
+		{pattern: "/srv/**/*.yaml", path: "/srv/app/config/app.yaml", expected: true},
+		{pattern: "/srv/**/*.yaml", path: "/srv/app.yaml", expected: true},
+		{pattern: "/srv/**/*.yaml", path: "/srv/app/config.json", expected: false},
+		{pattern: "/srv/app-?", path: "/srv/app-1/file", expected: true},
+		{pattern: "/srv/app-[!0-9]", path: "/srv/app-1/file", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			rule, err := newPathRule(tt.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if matches := rule.matches(tt.path); matches != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, matches)
+			}
+		})
+	}
+
+	if _, err := newPathRule("/srv/[app"); err == nil {
+		t.Errorf("expected error for unterminated character class")
+	}
+}
+
+func TestPathPolicyCheck(t *testing.T) {
+	tempDir := t.TempDir()
+	allowed := filepath.Join(tempDir, "allowed")
+	outside := filepath.Join(tempDir, "outside")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing.txt"), filepath.Join(allowed, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := newPathPolicy([]string{allowed}, []string{filepath.Join(allowed, "secrets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path  string
+		error string
+	}{
+		{path: filepath.Join(allowed, "new", "file.txt")},
+		{path: filepath.Join(outside, "file.txt"), error: "not matched by any allowed_paths rule"},
+		{path: filepath.Join(allowed, "link", "file.txt"), error: "resolves to " + filepath.Join(outside, "file.txt")},
+		{path: filepath.Join(allowed, "dangling"), error: "resolves to " + filepath.Join(outside, "missing.txt")},
+		{path: filepath.Join(allowed, "secrets", "token"), error: `denied by denied_paths rule "` + filepath.Join(allowed, "secrets") + `"`},
+		{path: allowed + "/new/../file.txt", error: `must not contain ".."`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := policy.check(tt.path)
+			if tt.error == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("expected error containing %q, got %v", tt.error, err)
+			}
+		})
+	}
+
+	// Without rules, everything is allowed
+	if err := (pathPolicy{}).check("../file.txt"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
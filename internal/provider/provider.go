@@ -2,23 +2,141 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type LocalProviderModel struct {
-	// No configuration needed for local provider
+	BaseDir              types.String          `tfsdk:"base_dir"`
+	DefaultInterpreter   types.List            `tfsdk:"default_interpreter"`
+	DefaultEnvironment   types.Map             `tfsdk:"default_environment"`
+	DefaultTimeout       types.String          `tfsdk:"default_timeout"`
+	DefaultFailIfNonzero types.Bool            `tfsdk:"default_fail_if_nonzero"`
+	AllowedPaths         types.List            `tfsdk:"allowed_paths"`
+	DeniedPaths          types.List            `tfsdk:"denied_paths"`
+	ExecPolicy           *LocalExecPolicyModel `tfsdk:"exec_policy"`
+}
+
+// LocalExecPolicyModel describes the exec_policy block of the provider.
+type LocalExecPolicyModel struct {
+	AllowedExecutables types.List `tfsdk:"allowed_executables"`
+	DeniedCommands     types.List `tfsdk:"denied_commands"`
+	DisableExec        types.Bool `tfsdk:"disable_exec"`
 }
 
 var LocalProviderSchema = schema.Schema{
 	Description: "Provider for managing local files and executing local commands",
+	Attributes: map[string]schema.Attribute{
+		"base_dir":                schema.StringAttribute{Optional: true, Description: "Base directory that relative working_dir paths are resolved against. Defaults to the TF_LOCAL_BASE_DIR environment variable, or the directory Terraform is run from."},
+		"default_interpreter":     schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Interpreter used by tf_local_exec when interpreter is not set, e.g. [\"bash\", \"-euo\", \"pipefail\", \"-c\"]. Defaults to [\"sh\", \"-c\"]."},
+		"default_environment":     schema.MapAttribute{ElementType: types.StringType, Optional: true, Description: "Environment variables set for every tf_local_exec command. The command's environment and sensitive_environment take precedence."},
+		"default_timeout":         schema.StringAttribute{Optional: true, Description: "Timeout used by tf_local_exec when timeout is not set, e.g. '5m'. No limit if not specified."},
+		"default_fail_if_nonzero": schema.BoolAttribute{Optional: true, Description: "Value of fail_if_nonzero for tf_local_exec when it is not set. It applies when a resource is created; existing resources keep their value, so that changing it does not run their commands again. Defaults to true."},
+		"allowed_paths":           schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Glob patterns of the paths tf_local_file and tf_dotenv_file may read and write, e.g. [\"/srv/app/**\"]. A pattern naming a directory covers everything beneath it. Paths are matched after resolving symlinks. All paths are allowed if not specified."},
+		"denied_paths":            schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Glob patterns of the paths tf_local_file and tf_dotenv_file may not read or write, e.g. [\"~/.ssh\"]. Takes precedence over allowed_paths."},
+	},
+	Blocks: map[string]schema.Block{
+		"exec_policy": schema.SingleNestedBlock{
+			Description: "Restrict the commands that tf_local_exec may run. Commands that violate the policy fail at plan time, before anything runs.",
+			Attributes: map[string]schema.Attribute{
+				"allowed_executables": schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Executables that may be run, as names looked up in PATH or paths, e.g. [\"bash\", \"/usr/bin/git\"]. Matched against the program the command runs, i.e. the interpreter or the first element of argv, after resolving it to an absolute path and resolving symlinks. All executables are allowed if not specified."},
+				"denied_commands":     schema.ListAttribute{ElementType: types.StringType, Optional: true, Description: "Regular expressions that commands may not match, e.g. [\"\\\\brm\\\\s+-rf\\\\b\"]. Matched against command, on_destroy and check_command, both on their own and joined by spaces with the interpreter, or argv joined by spaces."},
+				"disable_exec":        schema.BoolAttribute{Optional: true, Description: "Whether to disallow running any command. Defaults to false."},
+			},
+		},
+	},
+}
+
+// baseDirEnvVar is the environment variable that sets the base directory when
+// base_dir is not configured. It is also how provider functions, which are
+// called without the provider configuration, get a base directory.
+const baseDirEnvVar = "TF_LOCAL_BASE_DIR"
+
+// localProviderConfig holds the provider configuration passed to resources and
+// data sources through ResourceData and DataSourceData.
+type localProviderConfig struct {
+	BaseDir              string
+	DefaultInterpreter   []string
+	DefaultEnvironment   map[string]string
+	DefaultTimeout       time.Duration
+	DefaultFailIfNonzero bool
+	PathPolicy           pathPolicy
+	ExecPolicy           execPolicy
+}
+
+// resolvePath resolves a relative path against the configured base directory.
+// It is safe to call on a nil config, in which case the path is returned as is.
+func (c *localProviderConfig) resolvePath(p string) string {
+	if c == nil || c.BaseDir == "" || p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(c.BaseDir, p)
+}
+
+// checkPath returns an error if the path policy does not allow path. It is
+// safe to call on a nil config, in which case all paths are allowed.
+func (c *localProviderConfig) checkPath(p string) error {
+	if c == nil {
+		return nil
+	}
+	return c.PathPolicy.check(p)
+}
+
+// checkCommand returns an error if the exec policy does not allow command. It
+// is safe to call on a nil config, in which case all commands are allowed.
+func (c *localProviderConfig) checkCommand(command localCommand) error {
+	if c == nil {
+		return nil
+	}
+	return c.ExecPolicy.check(command)
+}
+
+// failIfNonzero returns the default of fail_if_nonzero. It is safe to call on
+// a nil config, in which case it returns true.
+func (c *localProviderConfig) failIfNonzero() bool {
+	if c == nil {
+		return true
+	}
+	return c.DefaultFailIfNonzero
+}
+
+// applyDefaults fills in the interpreter and timeout of a command if they are
+// not set, and adds the default environment underneath the command's own. It
+// is safe to call on a nil config, in which case the command is unchanged.
+func (c *localProviderConfig) applyDefaults(command *localCommand) {
+	if c == nil {
+		return
+	}
+	if len(command.Interpreter) == 0 {
+		command.Interpreter = c.DefaultInterpreter
+	}
+	if command.Timeout == 0 {
+		command.Timeout = c.DefaultTimeout
+	}
+	if len(c.DefaultEnvironment) > 0 {
+		environment := make(map[string]string, len(c.DefaultEnvironment)+len(command.Environment))
+		for name, value := range c.DefaultEnvironment {
+			environment[name] = value
+		}
+		for name, value := range command.Environment {
+			environment[name] = value
+		}
+		command.Environment = environment
+	}
 }
 
 var _ provider.Provider = &LocalProvider{}
+var _ provider.ProviderWithFunctions = &LocalProvider{}
 
 type LocalProvider struct {
 	version string
@@ -33,8 +151,72 @@ func (p *LocalProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 	resp.Schema = LocalProviderSchema
 }
 
-func (p *LocalProvider) Configure(_ context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	// No configuration needed
+func (p *LocalProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data LocalProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config := &localProviderConfig{DefaultFailIfNonzero: true}
+
+	baseDir := os.Getenv(baseDirEnvVar)
+	if !data.BaseDir.IsNull() && !data.BaseDir.IsUnknown() {
+		baseDir = data.BaseDir.ValueString()
+	}
+	if baseDir != "" {
+		resolved, err := resolveBaseDir(baseDir)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("base_dir"), "Invalid base directory", err.Error())
+			return
+		}
+		config.BaseDir = resolved
+	}
+
+	interpreter, diags := listValueToStrings(ctx, data.DefaultInterpreter)
+	resp.Diagnostics.Append(diags...)
+	environment, diags := mapValueToStrings(ctx, data.DefaultEnvironment)
+	resp.Diagnostics.Append(diags...)
+	timeout, diags := parseDuration(data.DefaultTimeout, path.Root("default_timeout"))
+	resp.Diagnostics.Append(diags...)
+	allowedPaths, diags := listValueToStrings(ctx, data.AllowedPaths)
+	resp.Diagnostics.Append(diags...)
+	deniedPaths, diags := listValueToStrings(ctx, data.DeniedPaths)
+	resp.Diagnostics.Append(diags...)
+	execPolicy, diags := data.ExecPolicy.execPolicy(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	config.DefaultInterpreter = interpreter
+	config.DefaultEnvironment = environment
+	config.DefaultTimeout = timeout
+	if !data.DefaultFailIfNonzero.IsNull() && !data.DefaultFailIfNonzero.IsUnknown() {
+		config.DefaultFailIfNonzero = data.DefaultFailIfNonzero.ValueBool()
+	}
+	policy, err := newPathPolicy(allowedPaths, deniedPaths)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid path policy", err.Error())
+		return
+	}
+	config.PathPolicy = policy
+	config.ExecPolicy = execPolicy
+
+	resp.ResourceData = config
+	resp.DataSourceData = config
+}
+
+// resolveBaseDir returns the absolute path of a base directory, which must exist.
+func resolveBaseDir(dir string) (string, error) {
+	baseDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(baseDir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not an existing directory", baseDir)
+	}
+	return baseDir, nil
 }
 
 func (p *LocalProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -46,13 +228,21 @@ func (p *LocalProvider) Resources(ctx context.Context) []func() resource.Resourc
 
 func (p *LocalProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
+		NewDotenvFileDataSource,
 		NewLocalExecDataSource,
 		NewLocalFileDataSource,
 	}
 }
 
 func (p *LocalProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewDotenvDecodeFunction,
+		NewDotenvEncodeFunction,
+		NewFileExistsFunction,
+		NewFileModeFunction,
+		NewGlobFunction,
+		NewSha256FileFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -2,10 +2,12 @@ package provider
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/joho/godotenv"
 )
 
@@ -37,3 +39,103 @@ func init() {
 func testAccPreCheck(t *testing.T) {
 	// No pre-check needed for local provider
 }
+
+func TestAccLocalProvider_Defaults(t *testing.T) {
+	providerConfig := `
+provider "tf" {
+  default_interpreter     = ["bash", "-c"]
+  default_timeout         = "2s"
+  default_fail_if_nonzero = false
+
+  default_environment = {
+    GREETING = "hello"
+    REGION   = "default"
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "tf_local_exec" "slow" {
+  command         = "sleep 30"
+  fail_if_nonzero = true
+}
+`,
+				ExpectError: regexp.MustCompile(`command timed out`),
+			},
+			{
+				Config: providerConfig + `
+resource "tf_local_exec" "interpreter" {
+  command = "echo $${BASH_VERSION:+bash}"
+}
+
+resource "tf_local_exec" "environment" {
+  command = "echo $GREETING $REGION"
+  environment = {
+    REGION = "eu-west-1"
+  }
+}
+
+resource "tf_local_exec" "nonzero" {
+  command = "exit 3"
+}
+
+resource "tf_local_exec" "overridden" {
+  command         = "echo $0"
+  interpreter     = ["sh", "-c"]
+  fail_if_nonzero = true
+}
+
+data "tf_local_exec" "nonzero" {
+  command = "exit 4"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("tf_local_exec.interpreter", "output", "bash\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.environment", "output", "hello eu-west-1\n"),
+					// The ID reflects the defaults the command runs with
+					resource.TestCheckResourceAttr("tf_local_exec.environment", "id", execID([]string{"bash", "-c", "echo $GREETING $REGION"}, map[string]string{"GREETING": "hello", "REGION": "eu-west-1"})),
+					resource.TestCheckResourceAttr("tf_local_exec.nonzero", "exit_code", "3"),
+					resource.TestCheckResourceAttr("tf_local_exec.nonzero", "fail_if_nonzero", "false"),
+					resource.TestCheckResourceAttr("tf_local_exec.overridden", "output", "sh\n"),
+					resource.TestCheckResourceAttr("tf_local_exec.overridden", "fail_if_nonzero", "true"),
+					resource.TestCheckResourceAttr("data.tf_local_exec.nonzero", "exit_code", "4"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccLocalProvider_DefaultFailIfNonzeroChanged(t *testing.T) {
+	config := func(failIfNonzero bool) string {
+		return fmt.Sprintf(`
+provider "tf" {
+  default_fail_if_nonzero = %t
+}
+
+resource "tf_local_exec" "nonzero" {
+  command = "exit 3"
+}
+`, failIfNonzero)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(false),
+				Check:  resource.TestCheckResourceAttr("tf_local_exec.nonzero", "fail_if_nonzero", "false"),
+			},
+			// Existing resources keep their value, so the command does not run again
+			{
+				Config:   config(true),
+				PlanOnly: true,
+			},
+		},
+	})
+}
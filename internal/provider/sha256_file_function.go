@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &Sha256FileFunction{}
+
+func NewSha256FileFunction() function.Function {
+	return &Sha256FileFunction{}
+}
+
+type Sha256FileFunction struct{}
+
+func (f *Sha256FileFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "sha256_file"
+}
+
+func (f *Sha256FileFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "SHA256 checksum of a file",
+		Description: "Returns the hex-encoded SHA256 checksum of the content of a file, without loading it into memory. Relative paths are resolved against TF_LOCAL_BASE_DIR, or the directory Terraform is run from.",
+		Parameters: []function.Parameter{
+			function.StringParameter{Name: "path", Description: "Path to the file"},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *Sha256FileFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var path string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &path))
+	if resp.Error != nil {
+		return
+	}
+
+	resolved, err := functionPath(path)
+	if err != nil {
+		resp.Error = function.NewFuncError(err.Error())
+		return
+	}
+	hashes, err := hashFile(resolved)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Failed to read file: %v", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, hashes.SHA256))
+}
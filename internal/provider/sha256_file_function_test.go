@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSha256FileFunction(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "test.txt"), []byte("Hello, World!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Relative paths are resolved against the base directory
+	t.Setenv(baseDirEnvVar, tempDir)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+output "absolute" {
+  value = provider::tf::sha256_file("%s/test.txt")
+}
+
+output "relative" {
+  value = provider::tf::sha256_file("test.txt")
+}
+`, tempDir),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("absolute", knownvalue.StringExact("dffd6021bb2bd5b0af676290809ec3a53191dd81c7f70a4b28688a362182986f")),
+					statecheck.ExpectKnownOutputValue("relative", knownvalue.StringExact("dffd6021bb2bd5b0af676290809ec3a53191dd81c7f70a4b28688a362182986f")),
+				},
+			},
+			{
+				Config: `
+output "missing" {
+  value = provider::tf::sha256_file("missing.txt")
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid value for "path" parameter`),
+			},
+		},
+	})
+}
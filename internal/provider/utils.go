@@ -1,34 +1,226 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"io/fs"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-// Helper function to parse file mode
-func parseFileMode(mode string) fs.FileMode {
-	var result uint32
-	if _, err := fmt.Sscanf(mode, "%o", &result); err != nil {
-		return 0644 // Default to 0644 if parsing fails
+// fileID returns the ID of a file, which is its absolute, cleaned path.
+func fileID(path string) (string, error) {
+	return filepath.Abs(path)
+}
+
+// functionPath resolves a relative path passed to a provider function against
+// TF_LOCAL_BASE_DIR. Terraform calls functions without configuring the
+// provider, so they can't use base_dir.
+func functionPath(path string) (string, error) {
+	baseDir, err := functionBaseDir()
+	if err != nil || baseDir == "" || path == "" || filepath.IsAbs(path) {
+		return path, err
+	}
+	return filepath.Join(baseDir, path), nil
+}
+
+// functionBaseDir returns the base directory of provider functions, or an
+// empty string if TF_LOCAL_BASE_DIR is not set.
+func functionBaseDir() (string, error) {
+	baseDir := os.Getenv(baseDirEnvVar)
+	if baseDir == "" {
+		return "", nil
 	}
-	return fs.FileMode(result)
+	baseDir, err := resolveBaseDir(baseDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", baseDirEnvVar, err)
+	}
+	return baseDir, nil
+}
+
+// execID returns the ID of a command, which is the hex-encoded SHA256 of the
+// arguments it runs, i.e. the interpreter and command or argv, and of its
+// environment. Sensitive environment variables are not part of the ID.
+func execID(args []string, environment map[string]string) string {
+	if environment == nil {
+		environment = map[string]string{}
+	}
+	// Map keys are sorted when encoding, so the encoding is canonical
+	encoded, _ := json.Marshal(struct {
+		Args        []string          `json:"args"`
+		Environment map[string]string `json:"environment"`
+	}{args, environment})
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
 }
 
-// generateFileID creates a unique identifier for a file based on its path and timestamp
-func generateFileID(path string, timestamp time.Time) string {
-	h := md5.New()
-	h.Write([]byte(path))
-	h.Write([]byte(timestamp.UTC().Format(time.RFC3339)))
-	return hex.EncodeToString(h.Sum(nil))
+// allKnown reports whether the values, and the elements of lists and maps
+// among them, are known.
+func allKnown(values ...attr.Value) bool {
+	for _, value := range values {
+		if value.IsUnknown() {
+			return false
+		}
+		switch v := value.(type) {
+		case types.List:
+			if !allKnown(v.Elements()...) {
+				return false
+			}
+		case types.Map:
+			for _, element := range v.Elements() {
+				if element.IsUnknown() {
+					return false
+				}
+			}
+		}
+	}
+	return true
 }
 
-// generateExecID creates a unique identifier for a command based on its command and timestamp
-func generateExecID(command string, timestamp time.Time) string {
-	h := md5.New()
-	h.Write([]byte(command))
-	h.Write([]byte(timestamp.UTC().Format(time.RFC3339)))
-	return hex.EncodeToString(h.Sum(nil))
+// listValueToStrings converts a list of strings to a Go slice, returning nil for null or unknown lists
+func listValueToStrings(ctx context.Context, list types.List) ([]string, diag.Diagnostics) {
+	if list.IsNull() || list.IsUnknown() {
+		return nil, nil
+	}
+	var result []string
+	diags := list.ElementsAs(ctx, &result, false)
+	return result, diags
+}
+
+// mapValueToStrings converts a map of strings to a Go map, returning nil for null or unknown maps
+func mapValueToStrings(ctx context.Context, m types.Map) (map[string]string, diag.Diagnostics) {
+	if m.IsNull() || m.IsUnknown() {
+		return nil, nil
+	}
+	var result map[string]string
+	diags := m.ElementsAs(ctx, &result, false)
+	return result, diags
+}
+
+// parseDuration parses a duration string such as "30s", returning zero for null or unknown values
+func parseDuration(value types.String, p path.Path) (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if value.IsNull() || value.IsUnknown() {
+		return 0, diags
+	}
+	d, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		diags.AddAttributeError(p, "Invalid duration", fmt.Sprintf("%q is not a valid duration, e.g. '30s' or '5m': %v", value.ValueString(), err))
+		return 0, diags
+	}
+	if d < 0 {
+		diags.AddAttributeError(p, "Invalid duration", fmt.Sprintf("%q must not be negative", value.ValueString()))
+		return 0, diags
+	}
+	return d, diags
+}
+
+// contextWithOptionalTimeout returns a context with the given timeout, or the parent context if the timeout is zero
+func contextWithOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// contentHashes holds the checksums exposed by the content_* attributes
+type contentHashes struct {
+	MD5          string
+	SHA1         string
+	SHA256       string
+	SHA512       string
+	Base64SHA256 string
+}
+
+// hashContent computes the checksums of content
+func hashContent(content []byte) contentHashes {
+	hashes, _ := hashReader(bytes.NewReader(content))
+	return hashes
+}
+
+// hashFile computes the checksums of a file without loading it into memory
+func hashFile(path string) (contentHashes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return contentHashes{}, err
+	}
+	defer f.Close()
+	return hashReader(f)
+}
+
+func hashReader(r io.Reader) (contentHashes, error) {
+	hMD5, hSHA1, hSHA256, hSHA512 := md5.New(), sha1.New(), sha256.New(), sha512.New()
+	if _, err := io.Copy(io.MultiWriter(hMD5, hSHA1, hSHA256, hSHA512), r); err != nil {
+		return contentHashes{}, err
+	}
+	sum256 := hSHA256.Sum(nil)
+	return contentHashes{
+		MD5:          hex.EncodeToString(hMD5.Sum(nil)),
+		SHA1:         hex.EncodeToString(hSHA1.Sum(nil)),
+		SHA256:       hex.EncodeToString(sum256),
+		SHA512:       hex.EncodeToString(hSHA512.Sum(nil)),
+		Base64SHA256: base64.StdEncoding.EncodeToString(sum256),
+	}, nil
+}
+
+// digestSaltSize is the size in bytes of the random salt of a content digest.
+const digestSaltSize = 16
+
+// contentDigest computes the salted digest of secret content, which is the
+// HMAC-SHA256 of the content keyed with the salt. It is returned as
+// "<salt>:<digest>", both hex-encoded, so that the salt can be reused to
+// compare the digest with other content.
+func contentDigest(salt []byte, r io.Reader) (string, error) {
+	mac := hmac.New(sha256.New, salt)
+	if _, err := io.Copy(mac, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt) + ":" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// digestSalt returns the salt of a content digest, or a new random salt if
+// the digest is empty or malformed.
+func digestSalt(digest string) ([]byte, error) {
+	if encoded, _, ok := strings.Cut(digest, ":"); ok {
+		if salt, err := hex.DecodeString(encoded); err == nil && len(salt) == digestSaltSize {
+			return salt, nil
+		}
+	}
+	salt := make([]byte, digestSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// fileDigest computes the content digest of a file, reusing the salt of the
+// previous digest, without loading the file into memory.
+func fileDigest(path string, previous string) (string, error) {
+	salt, err := digestSalt(previous)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return contentDigest(salt, f)
 }